@@ -1,45 +1,138 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/relipocere/gotune/internal/config"
 	"github.com/relipocere/gotune/internal/discord/bot"
+	"github.com/relipocere/gotune/internal/discord/types"
+	"github.com/relipocere/gotune/internal/health"
+	"github.com/relipocere/gotune/internal/locale"
 	l "github.com/relipocere/gotune/internal/logger"
+	"github.com/relipocere/gotune/internal/lyrics"
+	"github.com/relipocere/gotune/internal/metrics"
+	"github.com/relipocere/gotune/internal/storage"
+	"github.com/relipocere/gotune/internal/web"
 	"github.com/relipocere/gotune/internal/yt"
+	"go.uber.org/zap"
 )
 
 func main() {
+	configPath := flag.String("config", "", "path to the config file, config.yml in the working directory by default")
+	register := flag.Bool("register-commands", false, "register slash commands before starting")
+	check := flag.Bool("check-config", false, "validate the config and exit")
+	flag.Parse()
 
-	cfg, err := config.New("config", "yml", ".")
+	cfg, err := config.New(*configPath)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if *check {
+		fmt.Println("config is valid")
+		return
+	}
 
-	logger, err := l.NewLogger(l.SetLevel(cfg.LogLevel()))
+	logger, err := l.NewLogger(l.SetLevel(cfg.LogLevel))
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer logger.Sync()
 
-	e, err := yt.New(cfg.YtToken(), cfg.FileDirectory())
+	//Folders are created on the first start, so the audio folder is ready before the first download
+	for _, dir := range []string{cfg.FileDir, filepath.Dir(cfg.DBPath)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			logger.Fatal(err)
+		}
+	}
+
+	e, err := yt.New(cfg.YoutubeToken, cfg.FileDir)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	metrics.WatchCacheDirectory(cfg.FileDir)
+
+	store, err := storage.New(cfg.DBPath)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer store.Close()
+
+	var lyr types.LyricsProvider = lyrics.NewLRCLib()
+	if cfg.LyricsProvider == config.LyricsFile {
+		lyr = lyrics.NewFile(cfg.LyricsDir)
+	}
+
+	tr, err := locale.New(cfg.LocalesDir)
 	if err != nil {
 		logger.Fatal(err)
 	}
 
-	b := bot.New(cfg, logger, e)
+	b := bot.New(cfg, logger, metrics.InstrumentExtractor(e), store, lyr, tr)
 
-	if len(os.Args) > 1 {
-		args := os.Args[1:]
-		for _, arg := range args {
-			switch arg {
-			case "--register-commands":
-				b.RegisterSlashCommands()
-			default:
-				logger.Fatal("invalid argument")
+	if *register {
+		err := b.RegisterSlashCommands()
+		if err != nil {
+			logger.Fatal(err)
+		}
+	}
+	if addr := cfg.HTTPAddr; addr != "" {
+		mux := http.NewServeMux()
+		checker := health.New(b.GatewayState, cfg.FileDir, metrics.LastExtractionTime)
+		mux.Handle("/metrics", metrics.Handler())
+		mux.HandleFunc("/healthz", checker.Liveness)
+		mux.HandleFunc("/readyz", checker.Readiness)
+		if cfg.Dashboard || cfg.API {
+			server := web.New(logger, b.Dispatcher(), b.Extractor(), store, b, web.Options{
+				Token:        cfg.WebToken,
+				ClientID:     cfg.AppID,
+				ClientSecret: cfg.OAuthSecret,
+				RedirectURL:  cfg.OAuthRedirect,
+			})
+			if cfg.Dashboard {
+				server.Register(mux)
+			}
+			if cfg.API {
+				server.RegisterAPI(mux)
 			}
 		}
+		srv := newHTTPServer(addr, mux)
+		b.AttachHTTP(srv)
+		go serveHTTP(srv, logger)
 	}
 	b.Serve()
 }
+
+//readHeaderTimeout is how long clients may send request headers
+const readHeaderTimeout = 10 * time.Second
+
+//newHTTPServer creates the server of metrics, health checks, the dashboard and the API.
+//Contexts of the requests are cancelled once shutdown starts, so event streams end instead of holding it.
+func newHTTPServer(addr string, mux *http.ServeMux) *http.Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	srv.RegisterOnShutdown(cancel)
+	return srv
+}
+
+//serveHTTP serves the requests until the server is shut down.
+func serveHTTP(srv *http.Server, logger *zap.SugaredLogger) {
+	logger.Infow("Serving HTTP", "addr", srv.Addr)
+	err := srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Errorw(fmt.Sprintf("http: %s", err.Error()), "addr", srv.Addr)
+	}
+}
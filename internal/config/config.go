@@ -1,48 +1,241 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 )
 
-//Config is viper config reader.
+const (
+	//envPrefix starts environment variables overriding the config, e.g. GOTUNE_TOKEN
+	envPrefix = "GOTUNE"
+	//fileSuffix ends environment variables with paths to the files holding the values,
+	//e.g. GOTUNE_TOKEN_FILE=/run/secrets/token for Docker and Kubernetes secrets
+	fileSuffix = "_FILE"
+	//defaultFile is the config read if no file is chosen, it may be missing
+	defaultFile = "config.yml"
+)
+
+//Lyrics providers.
+const (
+	LyricsLRCLib = "lrclib"
+	LyricsFile   = "file"
+)
+
+//logLevels are the accepted levels of the logger.
+var logLevels = []string{"DEBUG", "INFO", "WARN", "WARNING", "ERROR", "FATAL", "PANIC"}
+
+//Config is the configuration of the bot.
+//Keys of the config file are in the mapstructure tags, timeouts are set in minutes.
 type Config struct {
-	viper *viper.Viper
+	//Token of the bot
+	Token string `mapstructure:"token"`
+	//AppID is the ID of the application which is used as the bot
+	AppID string `mapstructure:"appID"`
+	//DevGuildID is the guild commands are registered in instead of globally
+	DevGuildID string `mapstructure:"devGuildID"`
+	//YoutubeToken is the YouTube API token for song search
+	YoutubeToken string `mapstructure:"youtubeToken"`
+	//FileDir is the folder audio files are downloaded to
+	FileDir string `mapstructure:"fileDir"`
+	//DBPath is the file of the database
+	DBPath string `mapstructure:"dbPath"`
+	//IdleTimeout is how long the bot waits for new songs after the queue is empty
+	IdleTimeout time.Duration `mapstructure:"idleTimeout"`
+	//AloneTimeout is how long the bot stays alone in the voice channel, 0 to never leave
+	AloneTimeout time.Duration `mapstructure:"aloneTimeout"`
+	//PauseWhenAlone pauses playback while nobody is listening
+	PauseWhenAlone bool `mapstructure:"pauseWhenAlone"`
+	//LyricsProvider is one of the lyrics providers
+	LyricsProvider string `mapstructure:"lyricsProvider"`
+	//LyricsDir is the folder with lyrics files for the file provider
+	LyricsDir string `mapstructure:"lyricsDir"`
+	//LocalesDir is the folder with translations overriding the built-in ones
+	LocalesDir string `mapstructure:"localesDir"`
+	//PrefixCommands enables message commands, which need the privileged Message Content intent
+	PrefixCommands bool `mapstructure:"prefixCommands"`
+	//HTTPAddr is the address of the HTTP listener serving metrics and health checks, empty disables it
+	HTTPAddr string `mapstructure:"httpAddr"`
+	//Dashboard serves the web dashboard on the HTTP listener
+	Dashboard bool `mapstructure:"dashboard"`
+	//API serves the JSON API on the HTTP listener
+	API bool `mapstructure:"api"`
+	//WebToken signs in to the dashboard without Discord and authenticates API requests, empty disables it
+	WebToken string `mapstructure:"webToken"`
+	//OAuthSecret is the client secret of the application for Discord sign-in, empty disables it
+	OAuthSecret string `mapstructure:"oauthSecret"`
+	//OAuthRedirect is the address Discord redirects to after sign-in
+	OAuthRedirect string `mapstructure:"oauthRedirect"`
+	//LogLevel is one of the logger levels
+	LogLevel string `mapstructure:"logLevel"`
+}
+
+//defaults are the values of the keys missing from the config.
+var defaults = map[string]interface{}{
+	"fileDir":        "./audio",
+	"dbPath":         "./data/gotune.db",
+	"idleTimeout":    5,
+	"aloneTimeout":   5,
+	"pauseWhenAlone": true,
+	"lyricsProvider": LyricsLRCLib,
+	"lyricsDir":      "./lyrics",
+	"localesDir":     "./locales",
+	"logLevel":       "ERROR",
 }
 
-func New(name, ext, path string) (*Config, error) {
+//New reads the config file, overriding its values with environment variables and secret files,
+//and validates the result. All problems are reported at once.
+//Path chooses the file, if it's empty config.yml is read from the working directory, unless it's missing.
+func New(path string) (*Config, error) {
 	v := viper.New()
-	v.SetConfigName(name)
-	v.SetConfigType(ext)
-	v.AddConfigPath(path)
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	optional := path == ""
+	if optional {
+		path = defaultFile
+	}
+	v.SetConfigFile(path)
 	err := v.ReadInConfig()
-	if err != nil {
-		return nil, err
+	if err != nil && !(optional && errors.Is(err, os.ErrNotExist)) {
+		return nil, fmt.Errorf("unable to read config %s: %w", path, err)
 	}
 
-	return &Config{viper: v}, nil
-}
+	for _, key := range keys() {
+		env := envName(key)
+		if err := v.BindEnv(key, env); err != nil {
+			return nil, err
+		}
 
-//Token gets bot token.
-func (c *Config) Token() string {
-	return c.viper.GetString("token")
+		file, ok := os.LookupEnv(env + fileSuffix)
+		if !ok {
+			continue
+		}
+		if _, set := os.LookupEnv(env); set {
+			return nil, fmt.Errorf("both %s and %s are set", env, env+fileSuffix)
+		}
+		value, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", env+fileSuffix, err)
+		}
+		v.Set(key, strings.TrimSpace(string(value)))
+	}
+
+	//Fields which failed to decode are reported along with the invalid ones
+	var cfg Config
+	var errs []error
+	if err := v.Unmarshal(&cfg, viper.DecodeHook(minutesHook)); err != nil {
+		var decodeErr *mapstructure.Error
+		if !errors.As(err, &decodeErr) {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		for _, msg := range decodeErr.Errors {
+			errs = append(errs, errors.New(msg))
+		}
+	}
+	cfg.LogLevel = strings.ToUpper(cfg.LogLevel)
+	cfg.LyricsProvider = strings.ToLower(cfg.LyricsProvider)
+
+	errs = append(errs, cfg.validate()...)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
+	return &cfg, nil
 }
 
-//YtToken gets YouTube API token.
-func (c *Config) YtToken() string {
-	return c.viper.GetString("youtubeToken")
+//validate checks that required values are set and the values are allowed.
+func (c *Config) validate() []error {
+	var errs []error
+	require := func(key, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required, set it in the config or %s", key, envName(key)))
+		}
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s %q must be one of %s", key, value, strings.Join(allowed, ", ")))
+	}
+
+	require("token", c.Token)
+	require("appID", c.AppID)
+	require("youtubeToken", c.YoutubeToken)
+	require("fileDir", c.FileDir)
+	require("dbPath", c.DBPath)
+	if c.IdleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("idleTimeout can't be negative"))
+	}
+	if c.AloneTimeout < 0 {
+		errs = append(errs, fmt.Errorf("aloneTimeout can't be negative"))
+	}
+	oneOf("lyricsProvider", c.LyricsProvider, LyricsLRCLib, LyricsFile)
+	if c.LyricsProvider == LyricsFile {
+		require("lyricsDir", c.LyricsDir)
+	}
+	oneOf("logLevel", c.LogLevel, logLevels...)
+
+	if (c.Dashboard || c.API) && c.HTTPAddr == "" {
+		errs = append(errs, fmt.Errorf("httpAddr is required to serve the dashboard or the API"))
+	}
+	if c.API && c.WebToken == "" {
+		errs = append(errs, fmt.Errorf("webToken is required to authenticate API requests"))
+	}
+	if (c.OAuthSecret == "") != (c.OAuthRedirect == "") {
+		errs = append(errs, fmt.Errorf("oauthSecret and oauthRedirect must be set together"))
+	}
+	if c.Dashboard && c.WebToken == "" && c.OAuthSecret == "" {
+		errs = append(errs, fmt.Errorf("webToken or oauthSecret is required to sign in to the dashboard"))
+	}
+
+	return errs
 }
 
-//AppID gets app ID.
-func (c *Config) AppID() string {
-	return c.viper.GetString("appID")
+//minutesHook decodes durations from the number of minutes.
+func minutesHook(_ reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+	minutes, err := cast.ToIntE(data)
+	if err != nil {
+		return nil, fmt.Errorf("%q isn't a number of minutes", fmt.Sprint(data))
+	}
+	return time.Duration(minutes) * time.Minute, nil
 }
 
-//FileDirectory gets directory for media files.
-func (c *Config) FileDirectory() string {
-	return c.viper.GetString("fileDir")
+//keys returns the config keys from the tags of the fields.
+func keys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, t.Field(i).Tag.Get("mapstructure"))
+	}
+	return keys
 }
 
-//LogLevel ge.
-func (c *Config) LogLevel() string {
-	return c.viper.GetString("logLevel")
+//envName returns environment variable overriding the key, e.g. GOTUNE_YOUTUBE_TOKEN for youtubeToken.
+func envName(key string) string {
+	var b strings.Builder
+	b.WriteString(envPrefix)
+	b.WriteByte('_')
+	prev := rune(0)
+	for _, r := range key {
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+		prev = r
+	}
+	return b.String()
 }
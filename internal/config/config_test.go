@@ -0,0 +1,212 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const validConfig = `
+token: "bot-token"
+appID: "123"
+youtubeToken: "yt-token"
+`
+
+//writeConfig writes the config file to a temporary folder.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewDefaults(t *testing.T) {
+	cfg, err := New(writeConfig(t, validConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Token != "bot-token" || cfg.AppID != "123" || cfg.YoutubeToken != "yt-token" {
+		t.Errorf("values of the file aren't read: %+v", cfg)
+	}
+	if cfg.FileDir != "./audio" || cfg.DBPath != "./data/gotune.db" || cfg.LogLevel != "ERROR" || cfg.LyricsProvider != LyricsLRCLib {
+		t.Errorf("defaults aren't applied: %+v", cfg)
+	}
+	if cfg.IdleTimeout != 5*time.Minute || cfg.AloneTimeout != 5*time.Minute || !cfg.PauseWhenAlone {
+		t.Errorf("timeouts aren't applied in minutes: %+v", cfg)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		//errs are the parts of the messages which must be reported together
+		errs []string
+	}{
+		{
+			name:   "missing required keys",
+			config: `logLevel: "INFO"`,
+			errs:   []string{"token is required", "GOTUNE_TOKEN", "appID is required", "youtubeToken is required", "GOTUNE_YOUTUBE_TOKEN"},
+		},
+		{
+			name:   "empty required paths",
+			config: validConfig + "fileDir: \"\"\ndbPath: \"\"\n",
+			errs:   []string{"fileDir is required", "dbPath is required"},
+		},
+		{
+			name:   "timeout isn't a number",
+			config: validConfig + "idleTimeout: soon\n",
+			errs:   []string{`"soon" isn't a number of minutes`},
+		},
+		{
+			name:   "negative timeouts",
+			config: validConfig + "idleTimeout: -1\naloneTimeout: -5\n",
+			errs:   []string{"idleTimeout can't be negative", "aloneTimeout can't be negative"},
+		},
+		{
+			name:   "unknown log level",
+			config: validConfig + "logLevel: LOUD\n",
+			errs:   []string{`logLevel "LOUD" must be one of`},
+		},
+		{
+			name:   "unknown lyrics provider",
+			config: validConfig + "lyricsProvider: genius\n",
+			errs:   []string{`lyricsProvider "genius" must be one of lrclib, file`},
+		},
+		{
+			name:   "decode and validation errors together",
+			config: "pauseWhenAlone: maybe\nlogLevel: LOUD\n",
+			errs:   []string{"pauseWhenAlone", "token is required", "logLevel"},
+		},
+		{
+			name:   "web without listener and sign-in",
+			config: validConfig + "dashboard: true\napi: true\noauthSecret: secret\n",
+			errs: []string{
+				"httpAddr is required",
+				"webToken is required to authenticate API requests",
+				"oauthSecret and oauthRedirect must be set together",
+			},
+		},
+		{
+			name:   "dashboard without sign-in",
+			config: validConfig + "httpAddr: \":9090\"\ndashboard: true\n",
+			errs:   []string{"webToken or oauthSecret is required"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(writeConfig(t, tt.config))
+			if err == nil {
+				t.Fatal("New() must fail")
+			}
+			for _, msg := range tt.errs {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("error doesn't report %q:\n%s", msg, err)
+				}
+			}
+		})
+	}
+}
+
+func TestNewNormalizesEnums(t *testing.T) {
+	cfg, err := New(writeConfig(t, validConfig+"logLevel: warning\nlyricsProvider: FILE\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LogLevel != "WARNING" || cfg.LyricsProvider != LyricsFile {
+		t.Errorf("got logLevel %q and lyricsProvider %q", cfg.LogLevel, cfg.LyricsProvider)
+	}
+}
+
+func TestNewEnvironment(t *testing.T) {
+	t.Setenv("GOTUNE_TOKEN", "env-token")
+	t.Setenv("GOTUNE_IDLE_TIMEOUT", "10")
+	t.Setenv("GOTUNE_PAUSE_WHEN_ALONE", "false")
+
+	cfg, err := New(writeConfig(t, validConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Token != "env-token" {
+		t.Errorf("token = %q, environment must override the file", cfg.Token)
+	}
+	if cfg.IdleTimeout != 10*time.Minute || cfg.PauseWhenAlone {
+		t.Errorf("environment values aren't decoded: %+v", cfg)
+	}
+}
+
+func TestNewSecretFiles(t *testing.T) {
+	secret := func(t *testing.T, value string) string {
+		path := filepath.Join(t.TempDir(), "secret")
+		if err := os.WriteFile(path, []byte(value), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("read and trimmed", func(t *testing.T) {
+		t.Setenv("GOTUNE_TOKEN_FILE", secret(t, "file-token\n"))
+		t.Setenv("GOTUNE_YOUTUBE_TOKEN_FILE", secret(t, "  file-yt-token  "))
+		cfg, err := New(writeConfig(t, validConfig))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Token != "file-token" || cfg.YoutubeToken != "file-yt-token" {
+			t.Errorf("got token %q and youtubeToken %q", cfg.Token, cfg.YoutubeToken)
+		}
+	})
+
+	t.Run("satisfies required keys", func(t *testing.T) {
+		t.Setenv("GOTUNE_TOKEN_FILE", secret(t, "file-token"))
+		t.Setenv("GOTUNE_APP_ID", "123")
+		t.Setenv("GOTUNE_YOUTUBE_TOKEN_FILE", secret(t, "file-yt-token"))
+		if _, err := New(writeConfig(t, "")); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("both variable and file", func(t *testing.T) {
+		t.Setenv("GOTUNE_TOKEN", "env-token")
+		t.Setenv("GOTUNE_TOKEN_FILE", secret(t, "file-token"))
+		_, err := New(writeConfig(t, validConfig))
+		if err == nil || !strings.Contains(err.Error(), "both GOTUNE_TOKEN and GOTUNE_TOKEN_FILE are set") {
+			t.Errorf("New() error = %v", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv("GOTUNE_TOKEN_FILE", filepath.Join(t.TempDir(), "missing"))
+		_, err := New(writeConfig(t, validConfig))
+		if err == nil || !strings.Contains(err.Error(), "unable to read GOTUNE_TOKEN_FILE") {
+			t.Errorf("New() error = %v", err)
+		}
+	})
+}
+
+func TestNewMissingFile(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.yml"))
+	if err == nil || !strings.Contains(err.Error(), "unable to read config") {
+		t.Errorf("chosen config file must exist, error = %v", err)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"token":          "GOTUNE_TOKEN",
+		"appID":          "GOTUNE_APP_ID",
+		"youtubeToken":   "GOTUNE_YOUTUBE_TOKEN",
+		"dbPath":         "GOTUNE_DB_PATH",
+		"httpAddr":       "GOTUNE_HTTP_ADDR",
+		"oauthSecret":    "GOTUNE_OAUTH_SECRET",
+		"pauseWhenAlone": "GOTUNE_PAUSE_WHEN_ALONE",
+	}
+	for key, want := range tests {
+		if got := envName(key); got != want {
+			t.Errorf("envName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+//announce posts now playing messages and playback failures of the guild player.
+//Songs are announced in the announcement channel of the guild, if it's set,
+//otherwise in the channel the player was requested from.
+func (b *Bot) announce(e types.Event) {
+	failedToJoin := e.Type == types.EventPlayerDestroyed && e.Err != nil
+	if e.Type != types.EventTrackStarted && e.Type != types.EventTrackErrored && !failedToJoin {
+		return
+	}
+
+	t := b.guildPrinter(e.GuildID, "")
+	var embed *discordgo.MessageEmbed
+	switch e.Type {
+	case types.EventTrackStarted:
+		embed = types.TrackEmbed(t, t("now_playing"), *e.Song)
+	case types.EventTrackErrored:
+		embed = types.ErrorEmbed(t, t("play_error"))
+	case types.EventPlayerDestroyed:
+		//Member who requested the song waits for it in the command channel
+		b.sendAnnouncement(e, e.TextID, types.ErrorEmbed(t, t(explain(e.Err).key)))
+		return
+	}
+
+	b.sendAnnouncement(e, b.announceChannel(e.GuildID, e.TextID), embed)
+}
+
+//announceChannel returns the announcement channel of the guild, if it's set, otherwise the text channel.
+func (b *Bot) announceChannel(gID, textID string) string {
+	set, err := b.store.Settings(gID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", gID)
+	}
+	if set.AnnounceChannel != "" {
+		return set.AnnounceChannel
+	}
+	return textID
+}
+
+func (b *Bot) sendAnnouncement(e types.Event, chID string, embed *discordgo.MessageEmbed) {
+	_, err := b.s.ChannelMessageSendEmbed(chID, embed)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("announce: %s", err.Error()), "guildID", e.GuildID, "event", string(e.Type))
+	}
+}
@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	//searchDebounce is how long user has to stop typing before the search is made
+	searchDebounce = 400 * time.Millisecond
+	//minSearchLength is the minimum length of the query to search for
+	minSearchLength = 3
+	searchLimit     = 10
+)
+
+//debouncer tracks the latest autocomplete request of each user.
+type debouncer struct {
+	mux    *sync.Mutex
+	latest map[string]uint64
+	seq    uint64
+}
+
+func newDebouncer() *debouncer {
+	return &debouncer{
+		mux:    &sync.Mutex{},
+		latest: make(map[string]uint64),
+	}
+}
+
+//Wait waits for the debounce delay and reports
+//whether there were no newer requests from the user meanwhile.
+func (d *debouncer) Wait(uID string) bool {
+	d.mux.Lock()
+	d.seq++
+	seq := d.seq
+	d.latest[uID] = seq
+	d.mux.Unlock()
+
+	time.Sleep(searchDebounce)
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if d.latest[uID] != seq {
+		return false
+	}
+	delete(d.latest, uID)
+	return true
+}
+
+//playAutocomplete suggests songs matching the typed query.
+func (b *Bot) playAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	query := strings.TrimSpace(focusedOption(i).StringValue())
+	if len(query) < minSearchLength || strings.HasPrefix(query, "http") {
+		b.autocomplete(s, i, nil)
+		return
+	}
+
+	//Outdated requests are left unanswered, client shows only the latest one
+	if !b.debounce.Wait(i.Member.User.ID) {
+		return
+	}
+
+	results, err := b.extractor.Search(query, searchLimit)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("search: %s", err.Error()), "query", query)
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, res := range results {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncate(res.Title, maxChoiceLength),
+			Value: res.Link,
+		})
+	}
+	b.autocomplete(s, i, choices)
+}
+
+//queueAutocomplete suggests queue positions with titles of the songs.
+func (b *Bot) queueAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	typed := fmt.Sprint(focusedOption(i).Value)
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for n, song := range b.dispatcher.Queue(i.GuildID) {
+		pos := strconv.Itoa(n + 1)
+		if !strings.HasPrefix(pos, typed) {
+			continue
+		}
+
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncate(fmt.Sprintf("%s. %s", pos, song.Title), maxChoiceLength),
+			Value: n + 1,
+		})
+		if len(choices) == maxChoices {
+			break
+		}
+	}
+	b.autocomplete(s, i, choices)
+}
+
+//autocomplete responds to the autocomplete interaction with the choices.
+func (b *Bot) autocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, choices []*discordgo.ApplicationCommandOptionChoice) {
+	if choices == nil {
+		choices = []*discordgo.ApplicationCommandOptionChoice{}
+	}
+
+	b.reply(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+//focusedOption returns the option user is typing in, including options of the subcommands.
+func focusedOption(i *discordgo.InteractionCreate) *discordgo.ApplicationCommandInteractionDataOption {
+	if opt := findFocused(i.ApplicationCommandData().Options); opt != nil {
+		return opt
+	}
+	return &discordgo.ApplicationCommandInteractionDataOption{Type: discordgo.ApplicationCommandOptionString, Value: ""}
+}
+
+//findFocused looks for the focused option among the options and the options of the subcommands in them.
+func findFocused(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, opt := range options {
+		if opt.Focused {
+			return opt
+		}
+		if opt.Type == discordgo.ApplicationCommandOptionSubCommand || opt.Type == discordgo.ApplicationCommandOptionSubCommandGroup {
+			if nested := findFocused(opt.Options); nested != nil {
+				return nested
+			}
+		}
+	}
+	return nil
+}
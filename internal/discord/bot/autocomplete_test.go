@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestFocusedOption(t *testing.T) {
+	option := func(name string, typ discordgo.ApplicationCommandOptionType, value interface{}, focused bool, nested ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: typ, Value: value, Focused: focused, Options: nested}
+	}
+	const str, sub, group = discordgo.ApplicationCommandOptionString, discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup
+
+	tests := []struct {
+		name    string
+		options []*discordgo.ApplicationCommandInteractionDataOption
+		want    string
+	}{
+		{
+			name:    "top level",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{option("song", str, "never", true)},
+			want:    "never",
+		},
+		{
+			name:    "subcommand",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{option("language", sub, nil, false, option("language", str, "ru", true))},
+			want:    "ru",
+		},
+		{
+			name: "subcommand group",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{
+				option("group", group, nil, false, option("sub", sub, nil, false, option("a", str, "x", false), option("b", str, "y", true))),
+			},
+			want: "y",
+		},
+		{
+			name:    "nothing is focused",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{option("sub", sub, nil, false, option("a", str, "x", false))},
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+				Type: discordgo.InteractionApplicationCommandAutocomplete,
+				Data: discordgo.ApplicationCommandInteractionData{Options: tt.options},
+			}}
+			if got := focusedOption(i).StringValue(); got != tt.want {
+				t.Errorf("focusedOption() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,18 @@
 package bot
 
 import (
+	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/relipocere/gotune/internal/discord/player"
 
 	"github.com/relipocere/gotune/internal/config"
 
 	"github.com/relipocere/gotune/internal/discord/types"
+	"github.com/relipocere/gotune/internal/metrics"
 
 	"github.com/bwmarrin/discordgo"
 	"go.uber.org/zap"
@@ -21,133 +25,118 @@ type Bot struct {
 	cfg        *config.Config
 	extractor  types.Extractor
 	dispatcher types.Dispatcher
+	store      types.Store
+	lyrics     types.LyricsProvider
+	tr         types.Translator
+	debounce   *debouncer
+	registry   map[string]command
+	replies    *replies
+	//http is the server of the metrics, health checks and the dashboard, nil if it's disabled
+	http *http.Server
 }
 
 //New creates new Bot.
-func New(cfg *config.Config, l *zap.SugaredLogger, e types.Extractor) *Bot {
-	s, err := discordgo.New("Bot " + cfg.Token())
+func New(cfg *config.Config, l *zap.SugaredLogger, e types.Extractor, store types.Store, lyr types.LyricsProvider, tr types.Translator) *Bot {
+	s, err := discordgo.New("Bot " + cfg.Token)
 	if err != nil {
 		l.Fatal(err)
 	}
 
 	s.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildVoiceStates
+	//Message Content is privileged, gateway refuses the bot which requests it without the permission
+	if cfg.PrefixCommands {
+		s.Identify.Intents |= discordgo.IntentsGuildMessages | discordgo.IntentMessageContent
+	}
+	d := player.NewDispatcher(s, l, store, e, player.IdleOptions{
+		Idle:           cfg.IdleTimeout,
+		Alone:          cfg.AloneTimeout,
+		PauseWhenAlone: cfg.PauseWhenAlone,
+	})
 	b := &Bot{
 		s:          s,
 		log:        l,
 		cfg:        cfg,
 		extractor:  e,
-		dispatcher: player.NewDispatcher(s, l),
+		dispatcher: d,
+		store:      store,
+		lyrics:     lyr,
+		tr:         tr,
+		debounce:   newDebouncer(),
+		replies:    newReplies(),
 	}
 
+	b.registry = b.buildRegistry()
+	d.Subscribe(b.announce)
+	d.Subscribe(b.recordHistory)
+	d.Subscribe(metrics.Observe)
+
 	s.AddHandler(b.routeCommand)
+	if cfg.PrefixCommands {
+		s.AddHandler(b.routeMessage)
+	}
 	s.AddHandler(b.preventVoiceStateChange)
+	s.AddHandler(b.restorePlayer)
 	return b
 }
 
-//RegisterSlashCommands registers bot commands.
-func (b *Bot) RegisterSlashCommands() {
-	var commands = []*discordgo.ApplicationCommand{
-		{
-			Name:        "play",
-			Description: "Play a song or an album",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "song",
-					Description: "name of the song or youtube link",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "queue",
-			Description: "List the song queue",
-		},
-		{
-			Name:        "pause",
-			Description: "Pause the song",
-		},
-		{
-			Name:        "resume",
-			Description: "Resume the song",
-		},
-		{
-			Name:        "skip",
-			Description: "Skip currently playing song",
-		},
-		{
-			Name:        "skipto",
-			Description: "Skip to a certain queued song",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionInteger,
-					Name:        "position",
-					Description: "position of the song to skip to",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "stop",
-			Description: "Stop playing and leave",
-		},
-		{
-			Name:        "seek",
-			Description: "Play track at the specified minute and second",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionInteger,
-					Name:        "minute",
-					Description: "0-...",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionInteger,
-					Name:        "second",
-					Description: "0-60",
-					Required:    true,
-				},
-			},
-		},
+//routeCommand is the interaction command router.
+func (b *Bot) routeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand && i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		return
 	}
 
-	for _, v := range commands {
-		_, err := b.s.ApplicationCommandCreate(b.cfg.AppID(), "", v)
-		if err != nil {
-			b.log.Fatalw("Cannot create command", "err", err)
-		}
+	cmd, ok := b.registry[i.ApplicationCommandData().Name]
+	if !ok {
+		return
 	}
-}
 
-//routeCommand is the interaction command router.
-func (b *Bot) routeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	commandHandlers := map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
-		"play":   b.play,
-		"queue":  b.queue,
-		"pause":  b.pause,
-		"resume": b.resume,
-		"skip":   b.skip,
-		"skipto": b.skipTo,
-		"stop":   b.stop,
-		"seek":   b.seek,
-	}
-	if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
-		h(s, i)
+	if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		if cmd.autocomplete != nil {
+			cmd.autocomplete(s, i)
+		}
 		return
 	}
+	cmd.handler(s, i)
+}
+
+//Dispatcher returns dispatcher of the guild players.
+func (b *Bot) Dispatcher() types.Dispatcher {
+	return b.dispatcher
+}
+
+//Extractor returns extractor the songs are requested with.
+func (b *Bot) Extractor() types.Extractor {
+	return b.extractor
 }
 
-//Serve starts the bot and blocks until termination signal is received.
+//AttachHTTP makes the bot shut down the HTTP server together with the players.
+func (b *Bot) AttachHTTP(srv *http.Server) {
+	b.http = srv
+}
+
+//GatewayState reports whether the gateway is connected and when the last heartbeat was acknowledged.
+func (b *Bot) GatewayState() (bool, time.Time) {
+	b.s.RLock()
+	defer b.s.RUnlock()
+	return b.s.DataReady, b.s.LastHeartbeatAck
+}
+
+//Serve starts the bot and blocks until interrupt or termination signal is received.
 func (b *Bot) Serve() {
 	b.log.Warn("Bot is online")
 	err := b.s.Open()
 	if err != nil {
 		b.log.Fatal(err)
 	}
-	defer b.s.Close()
+
+	done := make(chan struct{})
+	go b.weeklyRecaps(done)
 
 	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
-	<-stop
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	sig := <-stop
+	b.log.Warnw("Shutting down", "signal", sig.String())
+	close(done)
+	b.shutdown()
 	b.log.Warn("Bot is offline")
 }
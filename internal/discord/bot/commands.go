@@ -9,125 +9,213 @@ import (
 )
 
 const (
-	msgInternalErr = "Internal error"
+	msgInternalErr     = "internal_error"
+	msgNoVoice         = "no_voice"
+	msgDownloadErr     = "download_error"
+	msgQueueFull       = "queue_full"
+	msgInvalidPosition = "invalid_position"
+	msgDone            = "done"
+)
+
+var (
+	removeCommand = &discordgo.ApplicationCommand{
+		Name:        "remove",
+		Description: "Remove a song from the queue",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "position",
+				Description:  "position of the song to remove",
+				Required:     true,
+				MinValue:     &minOne,
+				Autocomplete: true,
+			},
+		},
+	}
+	moveCommand = &discordgo.ApplicationCommand{
+		Name:        "move",
+		Description: "Move a song to another position in the queue",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "from",
+				Description:  "position of the song to move",
+				Required:     true,
+				MinValue:     &minOne,
+				Autocomplete: true,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "to",
+				Description:  "new position of the song",
+				Required:     true,
+				MinValue:     &minOne,
+				Autocomplete: true,
+			},
+		},
+	}
 )
 
 //play is the handler for play command.
 func (b *Bot) play(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	vID := findVoiceChannel(s, i.Member.User.ID)
+	opts := optionMap(i.ApplicationCommandData().Options)
+
+	vID := targetVoiceChannel(s, i, opts)
 	if vID == "" {
-		s.InteractionRespond(i.Interaction, types.TextInteractionResp("You must be in a voice channel"))
+		b.replyError(s, i, msgNoVoice)
+		return
+	}
+	req := opts["song"].StringValue()
+	set, err := b.store.Settings(i.GuildID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", i.GuildID)
+	}
+
+	if !set.SourceAllowed(types.QuerySource(req)) {
+		b.replyError(s, i, "source_disabled", types.QuerySource(req))
 		return
 	}
-	s.InteractionRespond(i.Interaction, types.TextInteractionResp("Retrieving the tunes 🎶 (this may take a while)"))
 
-	req := i.ApplicationCommandData().Options[0].StringValue()
+	if set.MaxQueue > 0 && len(b.dispatcher.Queue(i.GuildID)) >= set.MaxQueue {
+		b.replyError(s, i, msgQueueFull)
+		return
+	}
+	//Downloading takes longer than interaction response timeout
+	b.deferReply(s, i)
+
 	songs, err := b.extractor.Get(req)
 	if err != nil {
-		s.ChannelMessageSendEmbed(i.ChannelID, types.ErrorEmbed("Unable to download song(s)"))
-		b.log.Errorw(fmt.Sprintf("extractor: %s", err.Error()), "query", req)
+		b.editFailure(s, i, err, "query", req)
 		return
 	}
 
-	addedMsg := "Song was added to the queue"
-	if len(songs) > 1 {
-		addedMsg = fmt.Sprintf("%d songs were added to the queue", len(songs))
+	_, explicit := opts["channel"]
+	b.enqueue(s, i, vID, explicit, set, songs)
+}
+
+//enqueue adds songs requested by the member to the guild player,
+//replacing the deferred response with the result.
+//Bot is moved to the voice channel if it was chosen explicitly by a member allowed to use join command,
+//others only queue the songs to the existing player.
+func (b *Bot) enqueue(s *discordgo.Session, i *discordgo.InteractionCreate, vID string, explicit bool, set types.Settings, songs []types.Song) {
+	queued := len(b.dispatcher.Queue(i.GuildID))
+	if set.MaxQueue > 0 && queued+len(songs) > set.MaxQueue {
+		if queued >= set.MaxQueue {
+			b.editError(s, i, msgQueueFull)
+			return
+		}
+		songs = songs[:set.MaxQueue-queued]
 	}
-	s.ChannelMessageSend(i.ChannelID, addedMsg)
 
 	for ind := range songs {
 		songs[ind].Requester = i.Member.User
 	}
 
-	b.dispatcher.Play(i.GuildID, vID, i.ChannelID, songs)
-}
-
-//findVoiceChannel attempts to find in what voice channel user is in.
-func findVoiceChannel(s *discordgo.Session, uID string) string {
-	var voiceID string
-	for _, guild := range s.State.Guilds {
-		for _, vs := range guild.VoiceStates {
-			if vs.UserID == uID {
-				voiceID = vs.ChannelID
+	if explicit && isDJ(i.Member, set.DJRole) {
+		if cur := b.dispatcher.VoiceChannel(i.GuildID); cur != "" && cur != vID {
+			err := b.dispatcher.Join(i.GuildID, vID)
+			if err != nil {
+				b.logFailure(explain(err).level, err, "guildID", i.GuildID)
 			}
 		}
 	}
-	return voiceID
+
+	if err := b.dispatcher.Play(i.GuildID, vID, i.ChannelID, songs); err != nil {
+		b.editFailure(s, i, err)
+		return
+	}
+	if len(songs) > 1 {
+		b.editText(s, i, b.printer(i)("songs_added", len(songs)))
+	} else {
+		b.editEmbed(s, i, b.trackEmbed(i, "added_to_queue", songs[0]))
+	}
 }
 
-//seek is the handler for seek command.
-func (b *Bot) seek(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	min := int(i.ApplicationCommandData().Options[0].IntValue())
-	sec := int(i.ApplicationCommandData().Options[1].IntValue())
+//trackEmbed builds embed of the song with the message in the language of the guild.
+func (b *Bot) trackEmbed(i *discordgo.InteractionCreate, key string, song types.Song) *discordgo.MessageEmbed {
+	t := b.printer(i)
+	return types.TrackEmbed(t, t(key), song)
+}
+
+//join is the handler for join command.
+func (b *Bot) join(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	opts := optionMap(i.ApplicationCommandData().Options)
 
-	if !validTime(min, sec) {
-		s.InteractionRespond(i.Interaction, types.TextInteractionResp("Invalid seek time"))
+	vID := targetVoiceChannel(s, i, opts)
+	if vID == "" {
+		b.replyError(s, i, msgNoVoice)
 		return
 	}
 
-	timeSec := (min * 60) + sec
-	msg, err := b.dispatcher.Seek(i.GuildID, timeSec)
+	err := b.dispatcher.Join(i.GuildID, vID)
 	if err != nil {
-		s.InteractionRespond(i.Interaction, types.EmbedInteractionResp(types.ErrorEmbed(msgInternalErr)))
-		b.log.Errorw(err.Error(), "guildID", i.GuildID)
+		b.replyFailure(s, i, err)
 		return
 	}
-	s.InteractionRespond(i.Interaction, types.TextInteractionResp(msg))
+	b.replyText(s, i, b.printer(i)(msgDone))
 }
 
-//validTime checks wheter seek time is valid.
-func validTime(min, sec int) bool {
-	if min < 0 {
-		return false
+//optionMap indexes command options by their names.
+func optionMap(options []*discordgo.ApplicationCommandInteractionDataOption) map[string]*discordgo.ApplicationCommandInteractionDataOption {
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		opts[opt.Name] = opt
 	}
+	return opts
+}
 
-	if sec < 0 || sec > 60 {
-		return false
+//targetVoiceChannel returns voice channel from the channel option,
+//falling back to the voice channel the member is in.
+func targetVoiceChannel(s *discordgo.Session, i *discordgo.InteractionCreate, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) string {
+	if opt, ok := opts["channel"]; ok {
+		return opt.ChannelValue(s).ID
 	}
+	return findVoiceChannel(s, i.GuildID, i.Member.User.ID)
+}
 
-	return true
+//findVoiceChannel attempts to find in what voice channel of the guild user is in.
+func findVoiceChannel(s *discordgo.Session, gID, uID string) string {
+	vs, err := s.State.VoiceState(gID, uID)
+	if err != nil {
+		return ""
+	}
+	return vs.ChannelID
 }
 
 //queue is the handler for queue command.
 func (b *Bot) queue(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	titles := b.dispatcher.Queue(i.GuildID)
-	err := s.InteractionRespond(i.Interaction, types.EmbedInteractionResp(types.QueueEmbed(titles)))
-	if err != nil {
-		b.log.Errorw(err.Error(), "guildID", i.GuildID, "msgLength", len(titles))
-	}
+	b.replyEmbed(s, i, types.QueueEmbed(b.printer(i), titles))
 }
 
 //pause is the handler for pause command.
 func (b *Bot) pause(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	msg, err := b.dispatcher.Pause(i.GuildID)
+	err := b.dispatcher.Pause(i.GuildID)
 	if err != nil {
-		s.InteractionRespond(i.Interaction, types.EmbedInteractionResp(types.ErrorEmbed(msgInternalErr)))
-		b.log.Errorw(err.Error(), "guildID", i.GuildID)
+		b.replyFailure(s, i, err)
 		return
 	}
-	s.InteractionRespond(i.Interaction, types.TextInteractionResp(msg))
+	b.replyText(s, i, b.printer(i)(msgDone))
 }
 
 //resume is the handler for resume command.
 func (b *Bot) resume(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	msg, err := b.dispatcher.Resume(i.GuildID)
+	err := b.dispatcher.Resume(i.GuildID)
 	if err != nil {
-		s.InteractionRespond(i.Interaction, types.EmbedInteractionResp(types.ErrorEmbed(msgInternalErr)))
-		b.log.Errorw(err.Error(), "guildID", i.GuildID)
+		b.replyFailure(s, i, err)
 		return
 	}
-	s.InteractionRespond(i.Interaction, types.TextInteractionResp(msg))
+	b.replyText(s, i, b.printer(i)(msgDone))
 }
 
 //skip is the handler for skip command.
 func (b *Bot) skip(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	msg, err := b.dispatcher.Skip(i.GuildID)
+	song, err := b.dispatcher.Skip(i.GuildID)
 	if err != nil {
-		s.InteractionRespond(i.Interaction, types.EmbedInteractionResp(types.ErrorEmbed(msgInternalErr)))
-		b.log.Errorw(err.Error(), "guildID", i.GuildID)
+		b.replyFailure(s, i, err)
 		return
 	}
-	s.InteractionRespond(i.Interaction, types.TextInteractionResp(msg))
+	b.replyText(s, i, b.printer(i)("song_skipped", song.Title))
 }
 
 //skipTo is the handler for skipto command.
@@ -136,17 +224,49 @@ func (b *Bot) skipTo(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	pos := int(i.ApplicationCommandData().Options[0].IntValue())
 	if !validQueuePosition(pos) {
-		s.InteractionRespond(i.Interaction, types.TextInteractionResp("Invalid queue position"))
+		b.replyError(s, i, msgInvalidPosition)
+		return
+	}
+
+	song, err := b.dispatcher.SkipTo(i.GuildID, pos)
+	if err != nil {
+		b.replyFailure(s, i, err)
+		return
+	}
+	b.replyText(s, i, b.printer(i)("skipped_to", song.Title))
+}
+
+//remove is the handler for remove command.
+func (b *Bot) remove(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	pos := int(optionMap(i.ApplicationCommandData().Options)["position"].IntValue())
+	if !validQueuePosition(pos) {
+		b.replyError(s, i, msgInvalidPosition)
+		return
+	}
+
+	song, err := b.dispatcher.Remove(i.GuildID, pos)
+	if err != nil {
+		b.replyFailure(s, i, err)
+		return
+	}
+	b.replyText(s, i, b.printer(i)("song_removed", song.Title))
+}
+
+//move is the handler for move command.
+func (b *Bot) move(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	opts := optionMap(i.ApplicationCommandData().Options)
+	from, to := int(opts["from"].IntValue()), int(opts["to"].IntValue())
+	if !validQueuePosition(from) || !validQueuePosition(to) {
+		b.replyError(s, i, msgInvalidPosition)
 		return
 	}
 
-	msg, err := b.dispatcher.SkipTo(i.GuildID, pos)
+	song, err := b.dispatcher.Move(i.GuildID, from, to)
 	if err != nil {
-		s.InteractionRespond(i.Interaction, types.EmbedInteractionResp(types.ErrorEmbed(msgInternalErr)))
-		b.log.Errorw(err.Error(), "guildID", i.GuildID)
+		b.replyFailure(s, i, err)
 		return
 	}
-	s.InteractionRespond(i.Interaction, types.TextInteractionResp(msg))
+	b.replyText(s, i, b.printer(i)("song_moved", song.Title, to))
 }
 
 //validQueuePosition checks whether skip to position is valid.
@@ -159,13 +279,12 @@ func validQueuePosition(pos int) bool {
 
 //stop is the handler for stop command.
 func (b *Bot) stop(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	msg, err := b.dispatcher.Stop(i.GuildID)
+	err := b.dispatcher.Stop(i.GuildID)
 	if err != nil {
-		s.InteractionRespond(i.Interaction, types.EmbedInteractionResp(types.ErrorEmbed(msgInternalErr)))
-		b.log.Errorw(err.Error(), "guildID", i.GuildID)
+		b.replyFailure(s, i, err)
 		return
 	}
-	s.InteractionRespond(i.Interaction, types.TextInteractionResp(msg))
+	b.replyText(s, i, b.printer(i)(msgDone))
 }
 
 //preventVoiceStateChange removes guild player, if bot state is forcefully changed.
@@ -174,15 +293,15 @@ func (b *Bot) preventVoiceStateChange(_ *discordgo.Session, v *discordgo.VoiceSt
 		return
 	}
 
-	if v.UserID != b.cfg.AppID() {
+	if v.UserID != b.cfg.AppID {
 		return
 	}
 
-	//If bot was moved from channel
-	if v.ChannelID != v.BeforeUpdate.ChannelID {
-		_, err := b.dispatcher.Stop(v.GuildID)
+	//If bot was moved from channel not by the join command
+	if v.ChannelID != v.BeforeUpdate.ChannelID && v.ChannelID != b.dispatcher.VoiceChannel(v.GuildID) {
+		err := b.dispatcher.Stop(v.GuildID)
 		if err != nil {
-			b.log.Errorw(err.Error(), "guildID", v.GuildID)
+			b.logFailure(explain(err).level, err, "guildID", v.GuildID)
 		}
 		b.log.Debug("preventVoiceStateChange trigerred")
 	}
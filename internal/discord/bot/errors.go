@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"errors"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap/zapcore"
+)
+
+//failure is the error explained to the member.
+type failure struct {
+	key   string
+	args  []interface{}
+	level zapcore.Level
+}
+
+//extractionFailures are the messages and log severities of the extraction failure reasons.
+var extractionFailures = map[string]failure{
+	types.ReasonNotFound:       {key: "extraction_not_found", level: zapcore.InfoLevel},
+	types.ReasonUnavailable:    {key: "extraction_unavailable", level: zapcore.WarnLevel},
+	types.ReasonAgeRestricted:  {key: "extraction_age_restricted", level: zapcore.WarnLevel},
+	types.ReasonSearchFailed:   {key: "extraction_search_failed", level: zapcore.ErrorLevel},
+	types.ReasonDownloadFailed: {key: msgDownloadErr, level: zapcore.ErrorLevel},
+}
+
+//explain chooses the message and the log severity of the error returned by the dispatcher or the extractor.
+//Mistakes of the member are logged at debug level, failures of the bot at error level.
+func explain(err error) failure {
+	var rangeErr *types.QueueRangeError
+	var joinErr *types.JoinError
+	var extErr *types.ExtractionError
+	switch {
+	case errors.Is(err, types.ErrNoPlayer):
+		return failure{key: msgNotPlaying, level: zapcore.DebugLevel}
+	case errors.Is(err, types.ErrNotPlaying):
+		return failure{key: msgNotPlaying, level: zapcore.DebugLevel}
+	case errors.Is(err, types.ErrAlreadyPaused):
+		return failure{key: "already_paused", level: zapcore.DebugLevel}
+	case errors.Is(err, types.ErrNotPaused):
+		return failure{key: "not_paused", level: zapcore.DebugLevel}
+	case errors.Is(err, types.ErrAlreadyInChannel):
+		return failure{key: "already_in_channel", level: zapcore.DebugLevel}
+	case errors.As(err, &rangeErr):
+		return failure{key: "queue_too_short", args: []interface{}{rangeErr.Len}, level: zapcore.DebugLevel}
+	case errors.Is(err, types.ErrQueueRange):
+		return failure{key: msgInvalidPosition, level: zapcore.DebugLevel}
+	case errors.Is(err, types.ErrPlayerUnresponsive):
+		return failure{key: "player_unresponsive", level: zapcore.ErrorLevel}
+	case errors.Is(err, types.ErrShuttingDown):
+		return failure{key: "restarting", level: zapcore.InfoLevel}
+	case errors.As(err, &joinErr):
+		return failure{key: "join_error", level: zapcore.ErrorLevel}
+	case errors.As(err, &extErr):
+		if f, ok := extractionFailures[extErr.Reason]; ok {
+			return f
+		}
+		return failure{key: msgDownloadErr, level: zapcore.ErrorLevel}
+	}
+	return failure{key: msgInternalErr, level: zapcore.ErrorLevel}
+}
+
+//replyFailure responds with the message explaining the error and logs it.
+func (b *Bot) replyFailure(s *discordgo.Session, i *discordgo.InteractionCreate, err error, keysAndValues ...interface{}) {
+	f := explain(err)
+	b.replyError(s, i, f.key, f.args...)
+	b.logFailure(f.level, err, append([]interface{}{"guildID", i.GuildID}, keysAndValues...)...)
+}
+
+//editFailure replaces the deferred response with the message explaining the error and logs it.
+func (b *Bot) editFailure(s *discordgo.Session, i *discordgo.InteractionCreate, err error, keysAndValues ...interface{}) {
+	f := explain(err)
+	b.editError(s, i, f.key, f.args...)
+	b.logFailure(f.level, err, append([]interface{}{"guildID", i.GuildID}, keysAndValues...)...)
+}
+
+//logFailure logs the error with the severity.
+func (b *Bot) logFailure(level zapcore.Level, err error, keysAndValues ...interface{}) {
+	switch level {
+	case zapcore.DebugLevel:
+		b.log.Debugw(err.Error(), keysAndValues...)
+	case zapcore.InfoLevel:
+		b.log.Infow(err.Error(), keysAndValues...)
+	case zapcore.WarnLevel:
+		b.log.Warnw(err.Error(), keysAndValues...)
+	default:
+		b.log.Errorw(err.Error(), keysAndValues...)
+	}
+}
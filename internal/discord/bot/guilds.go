@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+//Guilds returns guilds the bot is in, sorted by name.
+func (b *Bot) Guilds() []types.Guild {
+	b.s.State.RLock()
+	guilds := make([]types.Guild, 0, len(b.s.State.Guilds))
+	for _, g := range b.s.State.Guilds {
+		guilds = append(guilds, types.Guild{ID: g.ID, Name: g.Name})
+	}
+	b.s.State.RUnlock()
+
+	sort.Slice(guilds, func(i, j int) bool {
+		return guilds[i].Name < guilds[j].Name
+	})
+	return guilds
+}
+
+//VoiceChannels returns voice channels of the guild in the order they're shown in Discord.
+func (b *Bot) VoiceChannels(gID string) []types.Channel {
+	g, err := b.s.State.Guild(gID)
+	if err != nil {
+		return nil
+	}
+
+	b.s.State.RLock()
+	var voice []*discordgo.Channel
+	for _, ch := range g.Channels {
+		if ch.Type == discordgo.ChannelTypeGuildVoice || ch.Type == discordgo.ChannelTypeGuildStageVoice {
+			voice = append(voice, ch)
+		}
+	}
+	b.s.State.RUnlock()
+
+	sort.Slice(voice, func(i, j int) bool {
+		return voice[i].Position < voice[j].Position
+	})
+	channels := make([]types.Channel, 0, len(voice))
+	for _, ch := range voice {
+		channels = append(channels, types.Channel{ID: ch.ID, Name: ch.Name})
+	}
+	return channels
+}
+
+//MemberVoiceChannel returns voice channel the member is in, empty if the member isn't in one.
+func (b *Bot) MemberVoiceChannel(gID, uID string) string {
+	return findVoiceChannel(b.s, gID, uID)
+}
+
+//CanControl checks whether the user is the member of the guild allowed to control playback,
+//the same way DJ commands are checked.
+func (b *Bot) CanControl(gID, uID string) bool {
+	m, err := b.member(gID, uID)
+	if err != nil {
+		return false
+	}
+
+	set, err := b.store.Settings(gID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", gID)
+	}
+	return isDJ(m, set.DJRole)
+}
+
+//member looks up the member in the state, falling back to the API,
+//with permissions of the member in the guild filled in.
+func (b *Bot) member(gID, uID string) (*discordgo.Member, error) {
+	m, err := b.s.State.Member(gID, uID)
+	if err != nil {
+		m, err = b.s.GuildMember(gID, uID)
+		if err != nil {
+			return nil, err
+		}
+		m.GuildID = gID
+		b.s.State.MemberAdd(m)
+	}
+
+	g, err := b.s.State.Guild(gID)
+	if err != nil {
+		return nil, err
+	}
+	b.s.State.RLock()
+	perms := guildPermissions(g, m)
+	b.s.State.RUnlock()
+	//Member from the state is shared, so it's copied before permissions are set
+	withPerms := *m
+	withPerms.Permissions = perms
+	return &withPerms, nil
+}
+
+//guildPermissions sums permissions of the everyone role and the roles of the member.
+//Owner of the guild has all of them.
+func guildPermissions(g *discordgo.Guild, m *discordgo.Member) int64 {
+	if m.User != nil && g.OwnerID == m.User.ID {
+		return discordgo.PermissionAll
+	}
+
+	var perms int64
+	for _, role := range g.Roles {
+		if role.ID == g.ID {
+			perms |= role.Permissions
+			continue
+		}
+		for _, id := range m.Roles {
+			if role.ID == id {
+				perms |= role.Permissions
+				break
+			}
+		}
+	}
+	return perms
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+const historyPageSize = 10
+
+//historyCommand is the definition of the history command.
+var historyCommand = &discordgo.ApplicationCommand{
+	Name:        "history",
+	Description: "List recently played songs",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "page",
+			Description: "page of the history, starting from the most recent songs",
+			MinValue:    &minOne,
+		},
+	},
+}
+
+//history is the handler for history command.
+func (b *Bot) history(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	page := 1
+	if opt, ok := optionMap(i.ApplicationCommandData().Options)["page"]; ok {
+		page = int(opt.IntValue())
+	}
+
+	entries, total, err := b.store.History(i.GuildID, (page-1)*historyPageSize, historyPageSize)
+	if err != nil {
+		b.replyError(s, i, msgInternalErr)
+		b.log.Errorw(fmt.Sprintf("history: %s", err.Error()), "guildID", i.GuildID)
+		return
+	}
+
+	pages := (total + historyPageSize - 1) / historyPageSize
+	if pages < 1 {
+		pages = 1
+	}
+	b.replyEmbed(s, i, types.HistoryEmbed(b.printer(i), entries, page, pages))
+}
+
+//previous is the handler for previous command.
+//Last played song is added to the beginning of the queue.
+func (b *Bot) previous(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	vID := findVoiceChannel(s, i.GuildID, i.Member.User.ID)
+	if cur := b.dispatcher.VoiceChannel(i.GuildID); cur != "" {
+		vID = cur
+	}
+	if vID == "" {
+		b.replyError(s, i, msgNoVoice)
+		return
+	}
+
+	entries, _, err := b.store.History(i.GuildID, 0, 1)
+	if err != nil {
+		b.replyError(s, i, msgInternalErr)
+		b.log.Errorw(fmt.Sprintf("history: %s", err.Error()), "guildID", i.GuildID)
+		return
+	}
+	if len(entries) < 1 {
+		b.replyError(s, i, "nothing_played")
+		return
+	}
+	b.deferReply(s, i)
+
+	songs := b.fetchMissing([]types.Song{entries[0].Song})
+	if len(songs) < 1 {
+		b.editError(s, i, msgDownloadErr)
+		return
+	}
+
+	songs[0].Requester = i.Member.User
+	if err := b.dispatcher.PlayNext(i.GuildID, vID, i.ChannelID, songs); err != nil {
+		b.editFailure(s, i, err)
+		return
+	}
+	b.editEmbed(s, i, b.trackEmbed(i, "playing_next", songs[0]))
+}
+
+//recordHistory saves the songs which stopped playing to the history, statistics are computed from it.
+func (b *Bot) recordHistory(e types.Event) {
+	if e.Type != types.EventTrackFinished && e.Type != types.EventTrackSkipped {
+		return
+	}
+
+	err := b.store.AddHistory(types.HistoryEntry{
+		GuildID:   e.GuildID,
+		Song:      *e.Song,
+		StartedAt: e.StartedAt,
+		EndedAt:   e.Time,
+		Skipped:   e.Type == types.EventTrackSkipped,
+	})
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("history: %s", err.Error()), "guildID", e.GuildID)
+	}
+}
@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/relipocere/gotune/internal/discord/player"
+	"github.com/relipocere/gotune/internal/discord/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+//printer returns printer of the messages in the language of the interaction guild.
+func (b *Bot) printer(i *discordgo.InteractionCreate) types.Printer {
+	var loc string
+	if i.GuildLocale != nil {
+		loc = string(*i.GuildLocale)
+	}
+	return b.guildPrinter(i.GuildID, loc)
+}
+
+//guildPrinter returns printer of the messages in the language chosen in the guild settings,
+//falling back to the guild locale.
+func (b *Bot) guildPrinter(gID, loc string) types.Printer {
+	set, err := b.store.Settings(gID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", gID)
+	}
+	if loc == "" {
+		loc = player.GuildLocale(b.s, gID)
+	}
+	return b.tr.Printer(set.Language(loc))
+}
+
+//languageAutocomplete suggests locales having translations.
+func (b *Bot) languageAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	typed := strings.ToLower(focusedOption(i).StringValue())
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, loc := range b.tr.Locales() {
+		name := loc
+		if lang, ok := discordgo.Locales[discordgo.Locale(loc)]; ok {
+			name = fmt.Sprintf("%s (%s)", lang, loc)
+		}
+		if !strings.Contains(strings.ToLower(name), typed) {
+			continue
+		}
+
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: loc})
+		if len(choices) == maxChoices {
+			break
+		}
+	}
+	b.autocomplete(s, i, choices)
+}
+
+//knownLocale checks whether there are translations to the locale.
+func (b *Bot) knownLocale(loc string) bool {
+	for _, known := range b.tr.Locales() {
+		if known == loc {
+			return true
+		}
+	}
+	return false
+}
+
+//localize adds translations of the names and descriptions to the command.
+//Keys are cmd.<command>[.<option>...].name and .description, see sharedOptionKeys for the shared options.
+func (b *Bot) localize(cmd *discordgo.ApplicationCommand) {
+	key := "cmd." + cmd.Name
+	if names := b.tr.Localizations(key + ".name"); names != nil {
+		cmd.NameLocalizations = &names
+	}
+	if descriptions := b.tr.Localizations(key + ".description"); descriptions != nil {
+		cmd.DescriptionLocalizations = &descriptions
+	}
+	b.localizeOptions(key, cmd.Options)
+}
+
+//localizeOptions adds translations to the options, their suboptions and choices.
+func (b *Bot) localizeOptions(prefix string, options []*discordgo.ApplicationCommandOption) {
+	for _, opt := range options {
+		key := prefix + "." + opt.Name
+		if shared, ok := sharedOptionKeys[opt]; ok {
+			key = shared
+		}
+		opt.NameLocalizations = b.tr.Localizations(key + ".name")
+		opt.DescriptionLocalizations = b.tr.Localizations(key + ".description")
+		for _, choice := range opt.Choices {
+			choice.NameLocalizations = b.tr.Localizations(fmt.Sprintf("%s.%v.name", key, choice.Value))
+		}
+		b.localizeOptions(key, opt.Options)
+	}
+}
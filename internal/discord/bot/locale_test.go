@@ -0,0 +1,39 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//keyTranslator translates every key to itself in Russian.
+type keyTranslator struct{}
+
+func (keyTranslator) Printer(string) types.Printer { return nil }
+
+func (keyTranslator) Locales() []string { return nil }
+
+func (keyTranslator) Localizations(key string) map[discordgo.Locale]string {
+	return map[discordgo.Locale]string{discordgo.Russian: key}
+}
+
+func TestLocalizeSharedOptions(t *testing.T) {
+	b := &Bot{tr: keyTranslator{}}
+	b.localize(playlistCommand)
+
+	for _, sub := range playlistCommand.Options {
+		for _, opt := range sub.Options {
+			want := "cmd.playlist." + sub.Name + "." + opt.Name + ".description"
+			if shared, ok := sharedOptionKeys[opt]; ok {
+				want = shared + ".description"
+			}
+			if got := opt.DescriptionLocalizations[discordgo.Russian]; got != want {
+				t.Errorf("%s %s is localized with %q, want %q", sub.Name, opt.Name, got, want)
+			}
+		}
+	}
+	if got := playlistScopeOption.Choices[0].NameLocalizations[discordgo.Russian]; got != "cmd.playlist.options.scope.personal.name" {
+		t.Errorf("scope choice is localized with %q", got)
+	}
+}
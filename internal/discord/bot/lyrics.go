@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+	"github.com/relipocere/gotune/internal/lyrics"
+)
+
+const (
+	//followInterval is how often followed lyrics are updated
+	followInterval = 2 * time.Second
+	//followLimit is how long lyrics are followed, interaction token expires after 15 minutes
+	followLimit = 14 * time.Minute
+)
+
+//lyricsCommand is the definition of the lyrics command.
+var lyricsCommand = &discordgo.ApplicationCommand{
+	Name:        "lyrics",
+	Description: "Show lyrics of the current or named song",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "song",
+			Description: "name of the song, defaults to current song",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "follow",
+			Description: "follow playback of the current song, if synced lyrics are available",
+		},
+	},
+}
+
+//lyricsCmd is the handler for lyrics command.
+func (b *Bot) lyricsCmd(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	opts := optionMap(i.ApplicationCommandData().Options)
+
+	var title string
+	nowPlaying := false
+	if opt, ok := opts["song"]; ok {
+		title = opt.StringValue()
+	} else {
+		song, _, ok := b.dispatcher.NowPlaying(i.GuildID)
+		if !ok {
+			b.replyError(s, i, msgNotPlaying)
+			return
+		}
+		title = song.Title
+		nowPlaying = true
+	}
+
+	//Provider may take a while to respond
+	b.deferReply(s, i)
+
+	lyr, err := b.lyrics.Find(title)
+	if err != nil {
+		msg := msgInternalErr
+		if errors.Is(err, lyrics.ErrNotFound) {
+			msg = "lyrics_not_found"
+		} else {
+			b.log.Errorw(fmt.Sprintf("lyrics: %s", err.Error()), "title", title)
+		}
+		b.editError(s, i, msg)
+		return
+	}
+
+	if follow, ok := opts["follow"]; ok && follow.BoolValue() && nowPlaying && len(lyr.Synced) > 0 {
+		go b.followLyrics(s, i, title, lyr)
+		return
+	}
+
+	t := b.printer(i)
+	pages := types.SplitPages(lyr.Plain)
+	b.editEmbed(s, i, types.LyricsEmbed(t, lyr, pages[0], 1, len(pages)))
+	for n, page := range pages[1:] {
+		b.followUp(s, i, types.LyricsEmbed(t, lyr, page, n+2, len(pages)))
+	}
+}
+
+//followLyrics keeps updating the response with the lines around the playback position,
+//until the song changes.
+func (b *Bot) followLyrics(s *discordgo.Session, i *discordgo.InteractionCreate, title string, lyr types.Lyrics) {
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	deadline := time.After(followLimit)
+	t := b.printer(i)
+	for {
+		song, position, ok := b.dispatcher.NowPlaying(i.GuildID)
+		if !ok || song.Title != title {
+			return
+		}
+
+		//Failure is logged, following stops, e.g. when the message is deleted
+		err := b.editEmbed(s, i, types.SyncedLyricsEmbed(t, lyr, position))
+		if err != nil {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return
+		}
+	}
+}
@@ -0,0 +1,431 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+const (
+	msgNoPlaylist     = "no_playlist"
+	maxPlaylistName   = 50
+	errPlaylistFormat = "playlist: %s"
+)
+
+var (
+	playlistNameOption = &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "name",
+		Description: "name of the playlist",
+		Required:    true,
+		MaxLength:   maxPlaylistName,
+	}
+	playlistScopeOption = &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "scope",
+		Description: "personal or shared with the server, defaults to personal",
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: types.ScopePersonal, Value: types.ScopePersonal},
+			{Name: types.ScopeServer, Value: types.ScopeServer},
+		},
+	}
+	minOne = 1.0
+)
+
+//sharedOptionKeys are translation keys of the options shared by several commands.
+//Shared options are localized once, not per command using them.
+var sharedOptionKeys = map[*discordgo.ApplicationCommandOption]string{
+	playlistNameOption:  "cmd.playlist.options.name",
+	playlistScopeOption: "cmd.playlist.options.scope",
+}
+
+//playlistCommand is the definition of the playlist command group.
+var playlistCommand = &discordgo.ApplicationCommand{
+	Name:        "playlist",
+	Description: "Manage saved playlists",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "create",
+			Description: "Create new playlist",
+			Options: []*discordgo.ApplicationCommandOption{
+				playlistNameOption,
+				playlistScopeOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "from-queue",
+					Description: "fill the playlist with current song and the queue",
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "add",
+			Description: "Add a song to the playlist",
+			Options: []*discordgo.ApplicationCommandOption{
+				playlistNameOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "song",
+					Description: "name of the song or youtube link, defaults to current song",
+				},
+				playlistScopeOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "Remove a song from the playlist",
+			Options: []*discordgo.ApplicationCommandOption{
+				playlistNameOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "position",
+					Description: "position of the song in the playlist",
+					Required:    true,
+					MinValue:    &minOne,
+				},
+				playlistScopeOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "show",
+			Description: "Show songs of the playlist or list playlists if name is omitted",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "name of the playlist",
+					MaxLength:   maxPlaylistName,
+				},
+				playlistScopeOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "play",
+			Description: "Add songs of the playlist to the queue",
+			Options: []*discordgo.ApplicationCommandOption{
+				playlistNameOption,
+				playlistScopeOption,
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "voice channel to play in, defaults to yours",
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildVoice},
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "delete",
+			Description: "Delete the playlist",
+			Options: []*discordgo.ApplicationCommandOption{
+				playlistNameOption,
+				playlistScopeOption,
+			},
+		},
+	},
+}
+
+//playlistCmd is the handler for playlist command group.
+func (b *Bot) playlistCmd(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	sub := i.ApplicationCommandData().Options[0]
+	opts := optionMap(sub.Options)
+
+	scope := types.ScopePersonal
+	if opt, ok := opts["scope"]; ok {
+		scope = opt.StringValue()
+	}
+
+	var name string
+	if opt, ok := opts["name"]; ok {
+		name = opt.StringValue()
+	}
+
+	switch sub.Name {
+	case "create":
+		b.playlistCreate(s, i, scope, name, opts)
+	case "add":
+		b.playlistAdd(s, i, scope, name, opts)
+	case "remove":
+		b.playlistRemove(s, i, scope, name, int(opts["position"].IntValue()))
+	case "show":
+		b.playlistShow(s, i, scope, name)
+	case "play":
+		b.playlistPlay(s, i, scope, name, opts)
+	case "delete":
+		b.playlistDelete(s, i, scope, name)
+	}
+}
+
+//playlistCreate creates new playlist, optionally filled with the current queue.
+func (b *Bot) playlistCreate(s *discordgo.Session, i *discordgo.InteractionCreate, scope, name string, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	ownerID := scopeOwner(i, scope)
+	_, found, err := b.store.Playlist(scope, ownerID, name)
+	if err != nil {
+		b.playlistError(s, i, err)
+		return
+	}
+	if found {
+		b.replyError(s, i, "playlist_exists")
+		return
+	}
+
+	pl := types.Playlist{
+		Name:      name,
+		Scope:     scope,
+		OwnerID:   ownerID,
+		CreatorID: i.Member.User.ID,
+	}
+	if opt, ok := opts["from-queue"]; ok && opt.BoolValue() {
+		if song, _, ok := b.dispatcher.NowPlaying(i.GuildID); ok {
+			pl.Songs = append(pl.Songs, song)
+		}
+		pl.Songs = append(pl.Songs, b.dispatcher.Queue(i.GuildID)...)
+	}
+
+	err = b.savePlaylist(pl)
+	if err != nil {
+		b.playlistError(s, i, err)
+		return
+	}
+	b.replyEmbed(s, i, types.PlaylistEmbed(b.printer(i), pl))
+}
+
+//playlistAdd adds requested or currently playing song to the playlist.
+func (b *Bot) playlistAdd(s *discordgo.Session, i *discordgo.InteractionCreate, scope, name string, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	pl, ok := b.editablePlaylist(s, i, scope, name)
+	if !ok {
+		return
+	}
+
+	opt, ok := opts["song"]
+	if !ok {
+		song, _, playing := b.dispatcher.NowPlaying(i.GuildID)
+		if !playing {
+			b.replyError(s, i, msgNotPlaying)
+			return
+		}
+
+		pl.Songs = append(pl.Songs, song)
+		err := b.savePlaylist(pl)
+		if err != nil {
+			b.playlistError(s, i, err)
+			return
+		}
+		b.replyText(s, i, b.printer(i)("playlist_song_added", song.Title, pl.Name))
+		return
+	}
+
+	req := opt.StringValue()
+	set, err := b.store.Settings(i.GuildID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", i.GuildID)
+	}
+	if !set.SourceAllowed(types.QuerySource(req)) {
+		b.replyError(s, i, "source_disabled", types.QuerySource(req))
+		return
+	}
+	b.deferReply(s, i)
+
+	songs, err := b.extractor.Get(req)
+	if err != nil {
+		b.editFailure(s, i, err, "query", req)
+		return
+	}
+
+	pl.Songs = append(pl.Songs, songs...)
+	err = b.savePlaylist(pl)
+	if err != nil {
+		b.editError(s, i, msgInternalErr)
+		b.log.Errorw(fmt.Sprintf(errPlaylistFormat, err.Error()), "guildID", i.GuildID)
+		return
+	}
+	b.editText(s, i, b.printer(i)("playlist_songs_added", len(songs), pl.Name))
+}
+
+//playlistRemove removes song at the position from the playlist.
+func (b *Bot) playlistRemove(s *discordgo.Session, i *discordgo.InteractionCreate, scope, name string, pos int) {
+	pl, ok := b.editablePlaylist(s, i, scope, name)
+	if !ok {
+		return
+	}
+
+	if pos < 1 || pos > len(pl.Songs) {
+		b.replyError(s, i, "playlist_too_short", len(pl.Songs))
+		return
+	}
+
+	removed := pl.Songs[pos-1]
+	pl.Songs = append(pl.Songs[:pos-1], pl.Songs[pos:]...)
+	err := b.savePlaylist(pl)
+	if err != nil {
+		b.playlistError(s, i, err)
+		return
+	}
+	b.replyText(s, i, b.printer(i)("playlist_song_removed", removed.Title, pl.Name))
+}
+
+//playlistShow shows songs of the playlist or lists playlists, if name is empty.
+func (b *Bot) playlistShow(s *discordgo.Session, i *discordgo.InteractionCreate, scope, name string) {
+	if name == "" {
+		playlists, err := b.store.Playlists(scope, scopeOwner(i, scope))
+		if err != nil {
+			b.playlistError(s, i, err)
+			return
+		}
+		b.replyPlaylist(s, i, scope, types.PlaylistsEmbed(b.printer(i), scope, playlists))
+		return
+	}
+
+	pl, found, err := b.store.Playlist(scope, scopeOwner(i, scope), name)
+	if err != nil {
+		b.playlistError(s, i, err)
+		return
+	}
+	if !found {
+		b.replyError(s, i, msgNoPlaylist)
+		return
+	}
+	b.replyPlaylist(s, i, scope, types.PlaylistEmbed(b.printer(i), pl))
+}
+
+//replyPlaylist responds with the playlist embed, personal ones are shown only to the member.
+func (b *Bot) replyPlaylist(s *discordgo.Session, i *discordgo.InteractionCreate, scope string, embed *discordgo.MessageEmbed) {
+	if scope == types.ScopePersonal {
+		b.replyPrivate(s, i, embed)
+		return
+	}
+	b.replyEmbed(s, i, embed)
+}
+
+//playlistPlay adds songs of the playlist to the queue.
+func (b *Bot) playlistPlay(s *discordgo.Session, i *discordgo.InteractionCreate, scope, name string, opts map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	vID := targetVoiceChannel(s, i, opts)
+	if vID == "" {
+		b.replyError(s, i, msgNoVoice)
+		return
+	}
+
+	pl, found, err := b.store.Playlist(scope, scopeOwner(i, scope), name)
+	if err != nil {
+		b.playlistError(s, i, err)
+		return
+	}
+	if !found {
+		b.replyError(s, i, msgNoPlaylist)
+		return
+	}
+	if len(pl.Songs) < 1 {
+		b.replyError(s, i, "playlist_empty")
+		return
+	}
+
+	set, err := b.store.Settings(i.GuildID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", i.GuildID)
+	}
+	//Saved songs are played by their links
+	if !set.SourceAllowed(types.SourceLink) {
+		b.replyError(s, i, "source_disabled", types.SourceLink)
+		return
+	}
+	b.deferReply(s, i)
+
+	songs := b.fetchMissing(pl.Songs)
+	if len(songs) < 1 {
+		b.editError(s, i, msgDownloadErr)
+		return
+	}
+
+	_, explicit := opts["channel"]
+	b.enqueue(s, i, vID, explicit, set, songs)
+}
+
+//playlistDelete deletes the playlist.
+func (b *Bot) playlistDelete(s *discordgo.Session, i *discordgo.InteractionCreate, scope, name string) {
+	pl, ok := b.editablePlaylist(s, i, scope, name)
+	if !ok {
+		return
+	}
+
+	err := b.store.DeletePlaylist(pl.Scope, pl.OwnerID, pl.Name)
+	if err != nil {
+		b.playlistError(s, i, err)
+		return
+	}
+	b.replyText(s, i, b.printer(i)("playlist_deleted", pl.Name))
+}
+
+//editablePlaylist loads the playlist if member is allowed to change it.
+//Response is sent if the playlist can't be changed.
+func (b *Bot) editablePlaylist(s *discordgo.Session, i *discordgo.InteractionCreate, scope, name string) (types.Playlist, bool) {
+	pl, found, err := b.store.Playlist(scope, scopeOwner(i, scope), name)
+	if err != nil {
+		b.playlistError(s, i, err)
+		return pl, false
+	}
+	if !found {
+		b.replyError(s, i, msgNoPlaylist)
+		return pl, false
+	}
+
+	//Server playlists can be changed only by the creator and server managers
+	if pl.Scope == types.ScopeServer && pl.CreatorID != i.Member.User.ID && !isAdmin(i.Member) {
+		b.replyError(s, i, msgNoPermission)
+		return pl, false
+	}
+	return pl, true
+}
+
+//savePlaylist saves the playlist without requester of the songs.
+func (b *Bot) savePlaylist(pl types.Playlist) error {
+	songs := make([]types.Song, len(pl.Songs))
+	for n, song := range pl.Songs {
+		song.Requester = nil
+		songs[n] = song
+	}
+	pl.Songs = songs
+	return b.store.SavePlaylist(pl)
+}
+
+//fetchMissing downloads again songs whose files were removed from the cache.
+//Songs that can't be downloaded or are replaced by another video are skipped.
+func (b *Bot) fetchMissing(songs []types.Song) []types.Song {
+	available := make([]types.Song, 0, len(songs))
+	for _, song := range songs {
+		if fileExists(song.Path) {
+			available = append(available, song)
+			continue
+		}
+
+		fetched, err := b.extractor.Download(song.Link)
+		if err != nil {
+			b.logFailure(explain(err).level, err, "link", song.Link)
+			continue
+		}
+		if fetched[0].VideoID() != song.VideoID() {
+			b.log.Warnw("downloaded video doesn't match the saved song", "link", song.Link, "downloaded", fetched[0].Link)
+			continue
+		}
+		fetched[0].Title = song.Title
+		available = append(available, fetched[0])
+	}
+	return available
+}
+
+func (b *Bot) playlistError(s *discordgo.Session, i *discordgo.InteractionCreate, err error) {
+	b.replyError(s, i, msgInternalErr)
+	b.log.Errorw(fmt.Sprintf(errPlaylistFormat, err.Error()), "guildID", i.GuildID)
+}
+
+//scopeOwner returns owner of the playlists in the scope.
+func scopeOwner(i *discordgo.InteractionCreate, scope string) string {
+	if scope == types.ScopeServer {
+		return i.GuildID
+	}
+	return i.Member.User.ID
+}
@@ -0,0 +1,359 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/relipocere/gotune/internal/discord/player"
+	"github.com/relipocere/gotune/internal/discord/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+//replyTTL is how long responses to the prefix commands can be edited,
+//the same as interaction tokens are valid.
+const replyTTL = 15 * time.Minute
+
+var (
+	channelMention = regexp.MustCompile(`^<#(\d+)>$`)
+	roleMention    = regexp.MustCompile(`^<@&(\d+)>$`)
+)
+
+//routeMessage routes prefix commands from the messages to the same handlers as slash commands.
+//Commands are turned into interactions, which are answered with messages.
+func (b *Bot) routeMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.GuildID == "" || m.Author == nil || m.Author.Bot {
+		return
+	}
+
+	set, err := b.store.Settings(m.GuildID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", m.GuildID)
+		return
+	}
+	if set.Prefix == "" || !strings.HasPrefix(m.Content, set.Prefix) {
+		return
+	}
+
+	args := splitArgs(strings.TrimPrefix(m.Content, set.Prefix))
+	if len(args) < 1 {
+		return
+	}
+	cmd, ok := b.registry[strings.ToLower(args[0])]
+	if !ok {
+		return
+	}
+
+	opts, err := parseOptions(cmd.schema.Options, args[1:])
+	if err != nil {
+		t := b.tr.Printer(set.Language(player.GuildLocale(s, m.GuildID)))
+		msg := t("invalid_command", err.Error(), set.Prefix+usage(cmd.schema))
+		_, err = s.ChannelMessageSendEmbedReply(m.ChannelID, types.ErrorEmbed(t, msg), m.Reference())
+		if err != nil {
+			b.log.Errorw(fmt.Sprintf("response: %s", err.Error()), "guildID", m.GuildID, "command", cmd.schema.Name)
+		}
+		return
+	}
+
+	i, err := messageInteraction(s, m, cmd.schema.Name, opts)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("prefix command: %s", err.Error()), "guildID", m.GuildID, "command", cmd.schema.Name)
+		return
+	}
+	cmd.handler(s, i)
+}
+
+//messageInteraction builds the command interaction from the message.
+func messageInteraction(s *discordgo.Session, m *discordgo.MessageCreate, name string, opts []*discordgo.ApplicationCommandInteractionDataOption) (*discordgo.InteractionCreate, error) {
+	member := &discordgo.Member{}
+	if m.Member != nil {
+		*member = *m.Member
+	}
+	member.User = m.Author
+	member.GuildID = m.GuildID
+
+	//Interactions carry permissions of the member, messages don't
+	perms, err := s.UserChannelPermissions(m.Author.ID, m.ChannelID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get permissions: %w", err)
+	}
+	member.Permissions = perms
+
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:        m.ID,
+			Type:      discordgo.InteractionApplicationCommand,
+			GuildID:   m.GuildID,
+			ChannelID: m.ChannelID,
+			Member:    member,
+			Message:   m.Message,
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:        name,
+				CommandType: discordgo.ChatApplicationCommand,
+				Options:     opts,
+			},
+		},
+	}, nil
+}
+
+//fromMessage checks whether the interaction was built from the prefix command.
+func fromMessage(i *discordgo.InteractionCreate) bool {
+	return i.Type == discordgo.InteractionApplicationCommand && i.Message != nil
+}
+
+//splitArgs splits the command into arguments by spaces,
+//text in double quotes is a single argument, e.g. "my playlist".
+func splitArgs(content string) []string {
+	var args []string
+	var arg strings.Builder
+	quoted, started := false, false
+	for _, r := range content {
+		switch {
+		//Mobile clients replace the quotes with typographic ones
+		case r == '"' || r == '“' || r == '”':
+			quoted = !quoted
+			started = true
+		case unicode.IsSpace(r) && !quoted:
+			if started {
+				args = append(args, arg.String())
+				arg.Reset()
+				started = false
+			}
+		default:
+			arg.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+//parseOptions parses the arguments of the prefix command by the command options.
+//Channels and roles are taken from mentions anywhere in the arguments,
+//the rest are positional, and the last free text option takes all the arguments
+//except the ones matching the options after it.
+func parseOptions(options []*discordgo.ApplicationCommandOption, args []string) ([]*discordgo.ApplicationCommandInteractionDataOption, error) {
+	if len(options) > 0 && isSubCommand(options[0]) {
+		if len(args) < 1 {
+			return nil, fmt.Errorf("subcommand is missing")
+		}
+		for _, opt := range options {
+			if !strings.EqualFold(opt.Name, args[0]) {
+				continue
+			}
+
+			nested, err := parseOptions(opt.Options, args[1:])
+			if err != nil {
+				return nil, err
+			}
+			return []*discordgo.ApplicationCommandInteractionDataOption{{
+				Name:    opt.Name,
+				Type:    opt.Type,
+				Options: nested,
+			}}, nil
+		}
+		return nil, fmt.Errorf("unknown subcommand %s", args[0])
+	}
+
+	var parsed []*discordgo.ApplicationCommandInteractionDataOption
+	var positional []*discordgo.ApplicationCommandOption
+	for _, opt := range options {
+		var pattern *regexp.Regexp
+		switch opt.Type {
+		case discordgo.ApplicationCommandOptionChannel:
+			pattern = channelMention
+		case discordgo.ApplicationCommandOptionRole:
+			pattern = roleMention
+		default:
+			positional = append(positional, opt)
+			continue
+		}
+
+		found := false
+		for n, arg := range args {
+			if match := pattern.FindStringSubmatch(arg); match != nil {
+				parsed = append(parsed, &discordgo.ApplicationCommandInteractionDataOption{Name: opt.Name, Type: opt.Type, Value: match[1]})
+				args = append(args[:n:n], args[n+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found && opt.Required {
+			return nil, fmt.Errorf("%s is missing", opt.Name)
+		}
+	}
+
+	for n, opt := range positional {
+		if len(args) < 1 {
+			if opt.Required {
+				return nil, fmt.Errorf("%s is missing", opt.Name)
+			}
+			continue
+		}
+
+		arg := args[0]
+		args = args[1:]
+		if freeText(opt) && lastString(positional[n+1:]) {
+			take := len(args) - tailLength(positional[n+1:], args)
+			arg = strings.Join(append([]string{arg}, args[:take]...), " ")
+			args = args[take:]
+		}
+
+		value, err := optionValue(opt, arg)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, &discordgo.ApplicationCommandInteractionDataOption{Name: opt.Name, Type: opt.Type, Value: value})
+	}
+
+	if len(args) > 0 {
+		return nil, fmt.Errorf("too many arguments")
+	}
+	return parsed, nil
+}
+
+//lastString checks whether there are no free text options among the rest.
+func lastString(rest []*discordgo.ApplicationCommandOption) bool {
+	for _, opt := range rest {
+		if freeText(opt) {
+			return false
+		}
+	}
+	return true
+}
+
+//freeText checks whether the option is a string without the predefined choices.
+func freeText(opt *discordgo.ApplicationCommandOption) bool {
+	return opt.Type == discordgo.ApplicationCommandOptionString && len(opt.Choices) < 1
+}
+
+//tailLength returns how many of the last arguments are values of the rest of the options,
+//so the string option before them doesn't take them.
+func tailLength(rest []*discordgo.ApplicationCommandOption, args []string) int {
+	for j := min(len(rest), len(args)); j > 0; j-- {
+		tail := args[len(args)-j:]
+		valid := true
+		for k, arg := range tail {
+			if _, err := optionValue(rest[k], arg); err != nil {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			return j
+		}
+	}
+	return 0
+}
+
+//optionValue converts the argument to the value of the option,
+//the same way Discord sends it in the interaction.
+func optionValue(opt *discordgo.ApplicationCommandOption, arg string) (interface{}, error) {
+	switch opt.Type {
+	case discordgo.ApplicationCommandOptionInteger:
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", opt.Name)
+		}
+		if (opt.MinValue != nil && float64(n) < *opt.MinValue) || (opt.MaxValue != 0 && float64(n) > opt.MaxValue) {
+			return nil, fmt.Errorf("%s is out of range", opt.Name)
+		}
+		return float64(n), nil
+	case discordgo.ApplicationCommandOptionBoolean:
+		switch strings.ToLower(arg) {
+		case "true", "yes", "on", "1":
+			return true, nil
+		case "false", "no", "off", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%s must be on or off", opt.Name)
+	}
+
+	if len(opt.Choices) < 1 {
+		return arg, nil
+	}
+	for _, choice := range opt.Choices {
+		if strings.EqualFold(choice.Name, arg) || strings.EqualFold(fmt.Sprint(choice.Value), arg) {
+			return choice.Value, nil
+		}
+	}
+	return nil, fmt.Errorf("%s must be one of %s", opt.Name, choiceNames(opt.Choices))
+}
+
+//usage describes arguments of the prefix command.
+func usage(cmd *discordgo.ApplicationCommand) string {
+	parts := []string{cmd.Name}
+	if len(cmd.Options) > 0 && isSubCommand(cmd.Options[0]) {
+		var subs []string
+		for _, opt := range cmd.Options {
+			subs = append(subs, opt.Name)
+		}
+		return fmt.Sprintf("%s <%s> ...", cmd.Name, strings.Join(subs, "|"))
+	}
+
+	for _, opt := range cmd.Options {
+		name := opt.Name
+		if len(opt.Choices) > 0 {
+			name = choiceNames(opt.Choices)
+		}
+		if opt.Required {
+			parts = append(parts, fmt.Sprintf("<%s>", name))
+		} else {
+			parts = append(parts, fmt.Sprintf("[%s]", name))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func choiceNames(choices []*discordgo.ApplicationCommandOptionChoice) string {
+	names := make([]string, len(choices))
+	for n, choice := range choices {
+		names[n] = fmt.Sprint(choice.Value)
+	}
+	return strings.Join(names, "|")
+}
+
+func isSubCommand(opt *discordgo.ApplicationCommandOption) bool {
+	return opt.Type == discordgo.ApplicationCommandOptionSubCommand || opt.Type == discordgo.ApplicationCommandOptionSubCommandGroup
+}
+
+//replies remembers responses to the prefix commands,
+//so they can be edited the same way as interaction responses.
+type replies struct {
+	mux *sync.Mutex
+	ids map[string]string
+}
+
+func newReplies() *replies {
+	return &replies{
+		mux: &sync.Mutex{},
+		ids: make(map[string]string),
+	}
+}
+
+//Load returns ID of the response to the command message.
+func (r *replies) Load(cmdID string) (string, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	id, ok := r.ids[cmdID]
+	return id, ok
+}
+
+//Store remembers the response to the command message until it can't be edited anymore.
+func (r *replies) Store(cmdID, replyID string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.ids[cmdID] = replyID
+	time.AfterFunc(replyTTL, func() {
+		r.mux.Lock()
+		defer r.mux.Unlock()
+		delete(r.ids, cmdID)
+	})
+}
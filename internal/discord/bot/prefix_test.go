@@ -0,0 +1,236 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{content: "play never  gonna\tgive", want: []string{"play", "never", "gonna", "give"}},
+		{content: `playlist add "road trip" song`, want: []string{"playlist", "add", "road trip", "song"}},
+		{content: `name "" x`, want: []string{"name", "", "x"}},
+		{content: "add “road trip” song", want: []string{"add", "road trip", "song"}},
+		{content: `a"b c"d`, want: []string{"ab cd"}},
+		{content: `add "unclosed quote`, want: []string{"add", "unclosed quote"}},
+		{content: "   ", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			if got := splitArgs(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	maxTen := 10.0
+	play := []*discordgo.ApplicationCommandOption{
+		{Type: discordgo.ApplicationCommandOptionString, Name: "song", Required: true},
+		{Type: discordgo.ApplicationCommandOptionChannel, Name: "channel"},
+	}
+	queued := []*discordgo.ApplicationCommandOption{
+		{Type: discordgo.ApplicationCommandOptionString, Name: "song", Required: true},
+		{Type: discordgo.ApplicationCommandOptionInteger, Name: "position", MinValue: &minOne, MaxValue: maxTen},
+		{Type: discordgo.ApplicationCommandOptionBoolean, Name: "next"},
+	}
+	role := []*discordgo.ApplicationCommandOption{
+		{Type: discordgo.ApplicationCommandOptionRole, Name: "role", Required: true},
+		{Type: discordgo.ApplicationCommandOptionString, Name: "note"},
+	}
+
+	tests := []struct {
+		name    string
+		options []*discordgo.ApplicationCommandOption
+		args    []string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "free text tail",
+			options: play,
+			args:    []string{"never", "gonna", "give", "you", "up"},
+			want:    map[string]interface{}{"song": "never gonna give you up"},
+		},
+		{
+			name:    "channel mention inside the text",
+			options: play,
+			args:    []string{"never", "gonna", "<#123>", "give", "you", "up"},
+			want:    map[string]interface{}{"song": "never gonna give you up", "channel": "123"},
+		},
+		{
+			name:    "missing required",
+			options: play,
+			args:    []string{"<#123>"},
+			wantErr: true,
+		},
+		{
+			name:    "text before values of the next options",
+			options: queued,
+			args:    []string{"never", "gonna", "3", "on"},
+			want:    map[string]interface{}{"song": "never gonna", "position": 3.0, "next": true},
+		},
+		{
+			name:    "text before value of the next option",
+			options: queued,
+			args:    []string{"song", "2"},
+			want:    map[string]interface{}{"song": "song", "position": 2.0},
+		},
+		{
+			name:    "number out of range stays in the text",
+			options: queued,
+			args:    []string{"route", "66"},
+			want:    map[string]interface{}{"song": "route 66"},
+		},
+		{
+			name:    "quoted text before the text tail",
+			options: role,
+			args:    []string{"<@&55>", "one two", "three"},
+			want:    map[string]interface{}{"role": "55", "note": "one two three"},
+		},
+		{
+			name:    "role mention anywhere",
+			options: role,
+			args:    []string{"hello", "<@&55>"},
+			want:    map[string]interface{}{"role": "55", "note": "hello"},
+		},
+		{
+			name:    "role isn't a channel",
+			options: role,
+			args:    []string{"<#55>"},
+			wantErr: true,
+		},
+		{
+			name:    "choice",
+			options: []*discordgo.ApplicationCommandOption{playlistScopeOption},
+			args:    []string{"Personal"},
+			want:    map[string]interface{}{"scope": "personal"},
+		},
+		{
+			name:    "invalid choice",
+			options: []*discordgo.ApplicationCommandOption{playlistScopeOption},
+			args:    []string{"everyone"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid number",
+			options: removeCommand.Options,
+			args:    []string{"first"},
+			wantErr: true,
+		},
+		{
+			name:    "below minimum",
+			options: removeCommand.Options,
+			args:    []string{"0"},
+			wantErr: true,
+		},
+		{
+			name:    "too many arguments",
+			options: moveCommand.Options,
+			args:    []string{"1", "2", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "positions",
+			options: moveCommand.Options,
+			args:    []string{"3", "1"},
+			want:    map[string]interface{}{"from": 3.0, "to": 1.0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOptions(tt.options, tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseOptions() = %v, want error", optionValues(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseOptions() error = %v", err)
+			}
+			if values := optionValues(got); !reflect.DeepEqual(values, tt.want) {
+				t.Errorf("parseOptions() = %v, want %v", values, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSubcommandOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		sub     string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "quoted name, text and choice",
+			args: []string{"ADD", "road trip", "never", "gonna", "give", "you", "up", "Server"},
+			sub:  "add",
+			want: map[string]interface{}{"name": "road trip", "song": "never gonna give you up", "scope": "server"},
+		},
+		{
+			name: "unquoted name takes one argument",
+			args: []string{"add", "road", "trip"},
+			sub:  "add",
+			want: map[string]interface{}{"name": "road", "song": "trip"},
+		},
+		{
+			name: "channel mention",
+			args: []string{"play", "<#42>", "mix"},
+			sub:  "play",
+			want: map[string]interface{}{"name": "mix", "channel": "42"},
+		},
+		{
+			name: "value which isn't a choice stays in the name",
+			args: []string{"delete", "mix", "everyone"},
+			sub:  "delete",
+			want: map[string]interface{}{"name": "mix everyone"},
+		},
+		{
+			name:    "unknown subcommand",
+			args:    []string{"rename", "mix"},
+			wantErr: true,
+		},
+		{
+			name:    "missing subcommand",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOptions(playlistCommand.Options, tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseOptions() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseOptions() error = %v", err)
+			}
+			if len(got) != 1 || got[0].Name != tt.sub || got[0].Type != discordgo.ApplicationCommandOptionSubCommand {
+				t.Fatalf("parseOptions() = %v, want subcommand %s", got, tt.sub)
+			}
+			if values := optionValues(got[0].Options); !reflect.DeepEqual(values, tt.want) {
+				t.Errorf("options of %s = %v, want %v", tt.sub, values, tt.want)
+			}
+		})
+	}
+}
+
+//optionValues indexes values of the parsed options by their names.
+func optionValues(opts []*discordgo.ApplicationCommandInteractionDataOption) map[string]interface{} {
+	values := make(map[string]interface{}, len(opts))
+	for _, opt := range opts {
+		values[opt.Name] = opt.Value
+	}
+	return values
+}
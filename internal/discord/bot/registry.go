@@ -0,0 +1,254 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/relipocere/gotune/internal/metrics"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+//handlerFunc handles the interaction.
+type handlerFunc = func(s *discordgo.Session, i *discordgo.InteractionCreate)
+
+//access is who is allowed to use the command.
+type access int
+
+const (
+	accessEveryone access = iota
+	accessDJ
+	accessAdmin
+)
+
+//command is the slash command declaration: its schema, handlers and who can use it.
+type command struct {
+	schema       *discordgo.ApplicationCommand
+	handler      handlerFunc
+	autocomplete handlerFunc
+	access       access
+}
+
+//commands declares all the bot commands in the order they're registered.
+func (b *Bot) commands() []command {
+	return []command{
+		{
+			schema: &discordgo.ApplicationCommand{
+				Name:        "play",
+				Description: "Play a song or an album",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "song",
+						Description:  "name of the song or youtube link",
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "voice channel to play in, defaults to yours",
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildVoice},
+					},
+				},
+			},
+			handler:      b.play,
+			autocomplete: b.playAutocomplete,
+		},
+		{
+			schema: &discordgo.ApplicationCommand{
+				Name:        "join",
+				Description: "Move the bot to another voice channel keeping the queue",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "voice channel to move to, defaults to yours",
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildVoice},
+					},
+				},
+			},
+			handler: b.join,
+			access:  accessDJ,
+		},
+		{
+			schema: &discordgo.ApplicationCommand{
+				Name:        "queue",
+				Description: "List the song queue",
+			},
+			handler: b.queue,
+		},
+		{
+			schema: &discordgo.ApplicationCommand{
+				Name:        "pause",
+				Description: "Pause the song",
+			},
+			handler: b.pause,
+			access:  accessDJ,
+		},
+		{
+			schema: &discordgo.ApplicationCommand{
+				Name:        "resume",
+				Description: "Resume the song",
+			},
+			handler: b.resume,
+			access:  accessDJ,
+		},
+		{
+			schema: &discordgo.ApplicationCommand{
+				Name:        "skip",
+				Description: "Skip currently playing song",
+			},
+			handler: b.skip,
+			access:  accessDJ,
+		},
+		{
+			schema: &discordgo.ApplicationCommand{
+				Name:        "skipto",
+				Description: "Skip to a certain queued song",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionInteger,
+						Name:         "position",
+						Description:  "position of the song to skip to",
+						Required:     true,
+						MinValue:     &minOne,
+						Autocomplete: true,
+					},
+				},
+			},
+			handler:      b.skipTo,
+			autocomplete: b.queueAutocomplete,
+			access:       accessDJ,
+		},
+		{
+			schema:       removeCommand,
+			handler:      b.remove,
+			autocomplete: b.queueAutocomplete,
+			access:       accessDJ,
+		},
+		{
+			schema:       moveCommand,
+			handler:      b.move,
+			autocomplete: b.queueAutocomplete,
+			access:       accessDJ,
+		},
+		{
+			schema: &discordgo.ApplicationCommand{
+				Name:        "stop",
+				Description: "Stop playing and leave",
+			},
+			handler: b.stop,
+			access:  accessDJ,
+		},
+		{
+			schema:  seekCommand,
+			handler: b.seek,
+			access:  accessDJ,
+		},
+		{
+			schema:       chapterCommand,
+			handler:      b.chapter,
+			autocomplete: b.chapterAutocomplete,
+			access:       accessDJ,
+		},
+		{
+			schema: &discordgo.ApplicationCommand{
+				Name:        "nowplaying",
+				Description: "Show current song, its position and chapter",
+			},
+			handler: b.nowPlaying,
+		},
+		{
+			schema:       settingsCommand,
+			handler:      b.settingsCmd,
+			autocomplete: b.languageAutocomplete,
+			access:       accessAdmin,
+		},
+		{
+			schema:  playlistCommand,
+			handler: b.playlistCmd,
+		},
+		{
+			schema:  historyCommand,
+			handler: b.history,
+		},
+		{
+			schema:  statsCommand,
+			handler: b.statsCmd,
+		},
+		{
+			schema:  lyricsCommand,
+			handler: b.lyricsCmd,
+		},
+		{
+			schema: &discordgo.ApplicationCommand{
+				Name:        "previous",
+				Description: "Play the last played song next",
+			},
+			handler: b.previous,
+		},
+	}
+}
+
+//buildRegistry indexes the commands by their names.
+func (b *Bot) buildRegistry() map[string]command {
+	commands := b.commands()
+	reg := make(map[string]command, len(commands))
+	for _, cmd := range commands {
+		reg[cmd.schema.Name] = instrument(b.guard(cmd))
+	}
+	return reg
+}
+
+//instrument measures how long the command handler takes.
+func instrument(cmd command) command {
+	h := cmd.handler
+	duration := metrics.InteractionDuration.WithLabelValues(cmd.schema.Name)
+	cmd.handler = func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		start := time.Now()
+		h(s, i)
+		duration.Observe(metrics.Since(start))
+	}
+	return cmd
+}
+
+//guard applies access of the command to its handler and its default permissions.
+func (b *Bot) guard(cmd command) command {
+	switch cmd.access {
+	case accessDJ:
+		cmd.handler = b.requireDJ(cmd.handler)
+	case accessAdmin:
+		cmd.handler = b.requireAdmin(cmd.handler)
+		cmd.schema.DefaultMemberPermissions = &permManageGuild
+	}
+	return cmd
+}
+
+//RegisterSlashCommands overwrites the registered commands with the declared ones,
+//so changed commands are updated and the ones which no longer exist are removed.
+//Commands are registered in the dev guild if it's set, where updates are instant,
+//then global commands left from earlier registrations are removed, so they aren't shown twice.
+func (b *Bot) RegisterSlashCommands() error {
+	var schemas []*discordgo.ApplicationCommand
+	for _, cmd := range b.commands() {
+		schema := b.guard(cmd).schema
+		b.localize(schema)
+		schemas = append(schemas, schema)
+	}
+
+	registered, err := b.s.ApplicationCommandBulkOverwrite(b.cfg.AppID, b.cfg.DevGuildID, schemas)
+	if err != nil {
+		return fmt.Errorf("unable to register commands: %w", err)
+	}
+	b.log.Infow("Commands are registered", "count", len(registered), "guildID", b.cfg.DevGuildID)
+
+	if b.cfg.DevGuildID != "" {
+		_, err = b.s.ApplicationCommandBulkOverwrite(b.cfg.AppID, "", []*discordgo.ApplicationCommand{})
+		if err != nil {
+			return fmt.Errorf("unable to remove global commands: %w", err)
+		}
+		b.log.Info("Global commands are removed")
+	}
+	return nil
+}
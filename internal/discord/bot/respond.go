@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+//reply responds to the interaction.
+//Prefix commands are answered with the reply to the message.
+func (b *Bot) reply(s *discordgo.Session, i *discordgo.InteractionCreate, resp *discordgo.InteractionResponse) {
+	if !fromMessage(i) {
+		b.logResponse(i, s.InteractionRespond(i.Interaction, resp))
+		return
+	}
+
+	switch resp.Type {
+	case discordgo.InteractionResponseDeferredChannelMessageWithSource:
+		b.logResponse(i, s.ChannelTyping(i.ChannelID))
+	case discordgo.InteractionResponseChannelMessageWithSource:
+		b.sendReply(s, i, resp.Data.Content, resp.Data.Embeds)
+	}
+}
+
+//replyText responds to the interaction with the text.
+func (b *Bot) replyText(s *discordgo.Session, i *discordgo.InteractionCreate, msg string) {
+	b.reply(s, i, types.TextInteractionResp(msg))
+}
+
+//replyEmbed responds to the interaction with the embed.
+func (b *Bot) replyEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+	b.reply(s, i, types.EmbedInteractionResp(embed))
+}
+
+//replyPrivate responds with the embed only the member can see.
+func (b *Bot) replyPrivate(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+	b.reply(s, i, types.Ephemeral(types.EmbedInteractionResp(embed)))
+}
+
+//replyError responds with the error message with the key, only the member can see it.
+func (b *Bot) replyError(s *discordgo.Session, i *discordgo.InteractionCreate, key string, args ...interface{}) {
+	b.replyPrivate(s, i, b.errorEmbed(i, key, args...))
+}
+
+//deferReply acknowledges the interaction, so the response can be edited when the slow work is done.
+//Interactions not acknowledged in 3 seconds are failed by Discord.
+func (b *Bot) deferReply(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	b.reply(s, i, types.DeferredInteractionResp())
+}
+
+//editText replaces the response to the interaction with the text.
+func (b *Bot) editText(s *discordgo.Session, i *discordgo.InteractionCreate, msg string) {
+	if fromMessage(i) {
+		b.editReply(s, i, msg, nil)
+		return
+	}
+
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &msg,
+	})
+	b.logResponse(i, err)
+}
+
+//editEmbed replaces the response to the interaction with the embed.
+func (b *Bot) editEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) error {
+	if fromMessage(i) {
+		return b.editReply(s, i, "", []*discordgo.MessageEmbed{embed})
+	}
+
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	b.logResponse(i, err)
+	return err
+}
+
+//editError replaces the response to the interaction with the error message with the key,
+//only the member can see it.
+//Response can't become ephemeral once sent, so it's deleted and the error is sent as a follow-up.
+func (b *Bot) editError(s *discordgo.Session, i *discordgo.InteractionCreate, key string, args ...interface{}) {
+	embed := b.errorEmbed(i, key, args...)
+	if fromMessage(i) {
+		b.editReply(s, i, "", []*discordgo.MessageEmbed{embed})
+		return
+	}
+
+	err := s.InteractionResponseDelete(i.Interaction)
+	if err != nil {
+		b.logResponse(i, err)
+		b.editEmbed(s, i, embed)
+		return
+	}
+
+	_, err = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Flags:  discordgo.MessageFlagsEphemeral,
+	})
+	b.logResponse(i, err)
+}
+
+//errorEmbed builds embed of the error message with the key in the language of the guild.
+func (b *Bot) errorEmbed(i *discordgo.InteractionCreate, key string, args ...interface{}) *discordgo.MessageEmbed {
+	t := b.printer(i)
+	return types.ErrorEmbed(t, t(key, args...))
+}
+
+//followUp sends another message in response to the interaction.
+func (b *Bot) followUp(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+	if fromMessage(i) {
+		_, err := s.ChannelMessageSendEmbed(i.ChannelID, embed)
+		b.logResponse(i, err)
+		return
+	}
+
+	_, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed},
+	})
+	b.logResponse(i, err)
+}
+
+//sendReply replies to the prefix command message.
+func (b *Bot) sendReply(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embeds []*discordgo.MessageEmbed) error {
+	msg, err := s.ChannelMessageSendComplex(i.ChannelID, &discordgo.MessageSend{
+		Content:   content,
+		Embeds:    embeds,
+		Reference: i.Message.Reference(),
+	})
+	b.logResponse(i, err)
+	if err != nil {
+		return err
+	}
+	b.replies.Store(i.ID, msg.ID)
+	return nil
+}
+
+//editReply replaces the reply to the prefix command message,
+//it's sent if the command was answered only with typing.
+func (b *Bot) editReply(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embeds []*discordgo.MessageEmbed) error {
+	replyID, ok := b.replies.Load(i.ID)
+	if !ok {
+		return b.sendReply(s, i, content, embeds)
+	}
+
+	edit := discordgo.NewMessageEdit(i.ChannelID, replyID)
+	edit.Content = &content
+	if embeds == nil {
+		embeds = []*discordgo.MessageEmbed{}
+	}
+	edit.Embeds = &embeds
+	_, err := s.ChannelMessageEditComplex(edit)
+	b.logResponse(i, err)
+	return err
+}
+
+//logResponse logs the failure to respond to the interaction.
+func (b *Bot) logResponse(i *discordgo.InteractionCreate, err error) {
+	if err == nil {
+		return
+	}
+	b.log.Errorw(fmt.Sprintf("response: %s", err.Error()), "guildID", i.GuildID, "command", i.ApplicationCommandData().Name)
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/player"
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//restorePlayer resumes guild player saved before the restart,
+//if someone is still listening in its voice channel or the guild is in 24/7 mode.
+func (b *Bot) restorePlayer(s *discordgo.Session, g *discordgo.GuildCreate) {
+	st, found, err := b.store.State(g.ID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("state: %s", err.Error()), "guildID", g.ID)
+		return
+	}
+	if !found || b.dispatcher.VoiceChannel(g.ID) != "" {
+		return
+	}
+
+	set, err := b.store.Settings(g.ID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", g.ID)
+	}
+
+	//Players in 24/7 mode are restored even if nobody is listening
+	st = dropMissingSongs(st)
+	empty := st.Current == nil && len(st.Queue) < 1
+	if !set.AlwaysOn && (empty || player.Listeners(g.Guild, st.VoiceID, s.State.User.ID) < 1) {
+		b.log.Debugw("discarding saved player", "guildID", g.ID)
+		err = b.store.DeleteState(g.ID)
+		if err != nil {
+			b.log.Errorw(fmt.Sprintf("state: %s", err.Error()), "guildID", g.ID)
+		}
+		return
+	}
+
+	b.log.Infow("restoring player", "guildID", g.ID, "voiceID", st.VoiceID, "offset", st.Offset)
+	b.dispatcher.Restore(st)
+}
+
+//dropMissingSongs removes songs whose files no longer exist from the state.
+func dropMissingSongs(st types.PlayerState) types.PlayerState {
+	if st.Current != nil && !fileExists(st.Current.Path) {
+		st.Current = nil
+		st.Offset = 0
+	}
+
+	queue := make([]types.Song, 0, len(st.Queue))
+	for _, song := range st.Queue {
+		if fileExists(song.Path) {
+			queue = append(queue, song)
+		}
+	}
+	st.Queue = queue
+	return st
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
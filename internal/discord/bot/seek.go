@@ -0,0 +1,230 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+const (
+	msgNotPlaying       = "not_playing"
+	msgInvalidSeek      = "invalid_seek"
+	msgNoChapter        = "no_chapter"
+	msgSeekBeyondTheEnd = "seek_beyond_end"
+
+	//Limits of the autocomplete choices
+	maxChoices      = 25
+	maxChoiceLength = 100
+)
+
+//seekCommand is the definition of the seek command.
+var seekCommand = &discordgo.ApplicationCommand{
+	Name:        "seek",
+	Description: "Play track from the specified time",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "time",
+			Description: "1:23, 1:23:45, 90s, or relative +30, -15",
+			Required:    true,
+		},
+	},
+}
+
+//chapterCommand is the definition of the chapter command.
+var chapterCommand = &discordgo.ApplicationCommand{
+	Name:        "chapter",
+	Description: "Jump to the chapter of the current song",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "name",
+			Description:  "name of the chapter",
+			Required:     true,
+			Autocomplete: true,
+		},
+	},
+}
+
+//seek is the handler for seek command.
+func (b *Bot) seek(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	input := optionMap(i.ApplicationCommandData().Options)["time"].StringValue()
+	pos, relative, err := parseSeekTime(input)
+	if err != nil {
+		b.replyError(s, i, msgInvalidSeek)
+		return
+	}
+
+	song, cur, ok := b.dispatcher.NowPlaying(i.GuildID)
+	if !ok {
+		b.replyError(s, i, msgNotPlaying)
+		return
+	}
+
+	pos, ok = seekTarget(pos, relative, cur, song.Duration)
+	if !ok {
+		b.replyError(s, i, msgSeekBeyondTheEnd)
+		return
+	}
+
+	b.seekTo(s, i, pos)
+}
+
+//seekTarget returns the position to seek the song of the duration to, relative seeks start from the current position.
+//Seeking before the start jumps to the start, seeking past the end isn't possible.
+//Duration is 0 if it's unknown.
+func seekTarget(pos time.Duration, relative bool, cur, duration time.Duration) (time.Duration, bool) {
+	if relative {
+		pos += cur
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	if duration > 0 && pos >= duration {
+		return 0, false
+	}
+	return pos, true
+}
+
+//chapter is the handler for chapter command.
+func (b *Bot) chapter(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	song, _, ok := b.dispatcher.NowPlaying(i.GuildID)
+	if !ok {
+		b.replyError(s, i, msgNotPlaying)
+		return
+	}
+
+	name := optionMap(i.ApplicationCommandData().Options)["name"].StringValue()
+	chapters := matchChapters(song.Chapters, name)
+	if len(chapters) < 1 {
+		b.replyError(s, i, msgNoChapter)
+		return
+	}
+
+	//Exact match is preferred over partial ones
+	ch := chapters[0]
+	for _, c := range chapters {
+		if strings.EqualFold(c.Title, name) {
+			ch = c
+			break
+		}
+	}
+	b.seekTo(s, i, ch.Start)
+}
+
+//chapterAutocomplete suggests chapters of the current song matching the input.
+func (b *Bot) chapterAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	if song, _, ok := b.dispatcher.NowPlaying(i.GuildID); ok {
+		for _, ch := range matchChapters(song.Chapters, focusedOption(i).StringValue()) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncate(fmt.Sprintf("%s %s", types.FormatDuration(ch.Start), ch.Title), maxChoiceLength),
+				Value: truncate(ch.Title, maxChoiceLength),
+			})
+			if len(choices) == maxChoices {
+				break
+			}
+		}
+	}
+	b.autocomplete(s, i, choices)
+}
+
+//nowPlaying is the handler for nowplaying command.
+func (b *Bot) nowPlaying(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	song, pos, ok := b.dispatcher.NowPlaying(i.GuildID)
+	if !ok {
+		b.replyError(s, i, msgNotPlaying)
+		return
+	}
+	b.replyEmbed(s, i, types.NowPlayingEmbed(b.printer(i), song, pos))
+}
+
+//seekTo seeks current song to the position and responds with the result.
+func (b *Bot) seekTo(s *discordgo.Session, i *discordgo.InteractionCreate, pos time.Duration) {
+	err := b.dispatcher.Seek(i.GuildID, int(pos/time.Second))
+	if err != nil {
+		b.replyFailure(s, i, err)
+		return
+	}
+	b.replyText(s, i, b.printer(i)(msgDone))
+}
+
+//matchChapters returns chapters whose titles contain the input, ignoring case.
+func matchChapters(chapters []types.Chapter, input string) []types.Chapter {
+	input = strings.ToLower(strings.TrimSpace(input))
+	var matched []types.Chapter
+	for _, ch := range chapters {
+		if strings.Contains(strings.ToLower(ch.Title), input) {
+			matched = append(matched, ch)
+		}
+	}
+	return matched
+}
+
+//parseSeekTime parses seek time in one of the formats:
+//
+//1:23:45, 1:23, 90, 90s, 1m30s or relative +30, -15, +1:00.
+func parseSeekTime(input string) (t time.Duration, relative bool, err error) {
+	input = strings.TrimSpace(input)
+	sign := time.Duration(1)
+	if strings.HasPrefix(input, "+") || strings.HasPrefix(input, "-") {
+		relative = true
+		if input[0] == '-' {
+			sign = -1
+		}
+		input = input[1:]
+	}
+
+	switch {
+	case strings.Contains(input, ":"):
+		t, err = parseClock(input)
+	case strings.ContainsAny(input, "hms"):
+		t, err = time.ParseDuration(input)
+		if err == nil && t < 0 {
+			err = fmt.Errorf("negative duration")
+		}
+	default:
+		var sec int
+		sec, err = strconv.Atoi(input)
+		if err == nil && sec < 0 {
+			err = fmt.Errorf("negative seconds")
+		}
+		t = time.Duration(sec) * time.Second
+	}
+	return sign * t, relative, err
+}
+
+//parseClock parses time in h:mm:ss or m:ss format.
+//Minutes and seconds are from 0 to 59.
+func parseClock(input string) (time.Duration, error) {
+	parts := strings.Split(input, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("too many parts")
+	}
+
+	var total int
+	for n, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid part %q", part)
+		}
+		//The leading part isn't limited, e.g. 90:00 is an hour and a half
+		if v > 59 && n > 0 {
+			return 0, fmt.Errorf("part %q is out of range", part)
+		}
+		total = total*60 + v
+	}
+	return time.Duration(total) * time.Second, nil
+}
+
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
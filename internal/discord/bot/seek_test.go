@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSeekTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     time.Duration
+		relative bool
+		wantErr  bool
+	}{
+		{input: "1:23", want: 83 * time.Second},
+		{input: "0:59", want: 59 * time.Second},
+		{input: "59:59", want: 59*time.Minute + 59*time.Second},
+		{input: "60:00", want: time.Hour},
+		{input: "75:30", want: 75*time.Minute + 30*time.Second},
+		{input: "90:00", want: 90 * time.Minute},
+		{input: "1:23:45", want: time.Hour + 23*time.Minute + 45*time.Second},
+		{input: "0:00:05", want: 5 * time.Second},
+		{input: "100:00:00", want: 100 * time.Hour},
+		{input: " 90 ", want: 90 * time.Second},
+		{input: "90s", want: 90 * time.Second},
+		{input: "1m30s", want: 90 * time.Second},
+		{input: "+30", want: 30 * time.Second, relative: true},
+		{input: "-10", want: -10 * time.Second, relative: true},
+		{input: "+1:00", want: time.Minute, relative: true},
+		{input: "-1m", want: -time.Minute, relative: true},
+
+		{input: "1:60", wantErr: true},
+		{input: "1:60:00", wantErr: true},
+		{input: "1:00:60", wantErr: true},
+		{input: "1:2:3:4", wantErr: true},
+		{input: "1:-5", wantErr: true},
+		{input: "1:", wantErr: true},
+		{input: "+-5", wantErr: true},
+		{input: "--5", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, relative, err := parseSeekTime(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseSeekTime(%q) = %v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSeekTime(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want || relative != tt.relative {
+				t.Errorf("parseSeekTime(%q) = %v, %v, want %v, %v", tt.input, got, relative, tt.want, tt.relative)
+			}
+		})
+	}
+}
+
+func TestSeekTarget(t *testing.T) {
+	const duration = 3 * time.Minute
+	tests := []struct {
+		name     string
+		pos      time.Duration
+		relative bool
+		cur      time.Duration
+		duration time.Duration
+		want     time.Duration
+		wantOK   bool
+	}{
+		{name: "absolute", pos: time.Minute, cur: 2 * time.Minute, duration: duration, want: time.Minute, wantOK: true},
+		{name: "forward", pos: 30 * time.Second, relative: true, cur: time.Minute, duration: duration, want: 90 * time.Second, wantOK: true},
+		{name: "backward", pos: -10 * time.Second, relative: true, cur: time.Minute, duration: duration, want: 50 * time.Second, wantOK: true},
+		{name: "before the start", pos: -10 * time.Second, relative: true, cur: 5 * time.Second, duration: duration, want: 0, wantOK: true},
+		{name: "last second", pos: duration - time.Second, duration: duration, want: duration - time.Second, wantOK: true},
+		{name: "end", pos: duration, duration: duration},
+		{name: "past the end", pos: 30 * time.Second, relative: true, cur: duration - 10*time.Second, duration: duration},
+		{name: "unknown duration", pos: time.Hour, want: time.Hour, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := seekTarget(tt.pos, tt.relative, tt.cur, tt.duration)
+			if ok != tt.wantOK || ok && got != tt.want {
+				t.Errorf("seekTarget() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+const (
+	msgNoPermission = "no_permission"
+	maxPrefix       = 5
+	maxVolume       = 200
+)
+
+var (
+	permManageGuild int64 = discordgo.PermissionManageGuild
+	minZero               = 0.0
+)
+
+//settingsCommand is the definition of the settings command group.
+var settingsCommand = &discordgo.ApplicationCommand{
+	Name:        "settings",
+	Description: "View and change settings of the server",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "show",
+			Description: "Show current settings",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "volume",
+			Description: "Set default volume",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "percent",
+					Description: "0-200",
+					Required:    true,
+					MinValue:    &minZero,
+					MaxValue:    maxVolume,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "dj-role",
+			Description: "Set role required to control playback, omit to allow everyone",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "DJ role",
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "prefix",
+			Description: "Set prefix of message commands like !play, omit to disable them",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "prefix",
+					Description: "command prefix",
+					MaxLength:   maxPrefix,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "language",
+			Description: "Set language of the bot messages, omit to use server language",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "language",
+					Description:  "language of the messages",
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "announce-channel",
+			Description: "Set channel for now playing messages, omit to use command channel",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "announcement channel",
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "summary-channel",
+			Description: "Set channel for weekly listening recaps, omit to disable them",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "recap channel",
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "loop",
+			Description: "Set loop mode",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "mode",
+					Description: "loop mode",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "off", Value: types.LoopOff},
+						{Name: "track", Value: types.LoopTrack},
+						{Name: "queue", Value: types.LoopQueue},
+					},
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "autoplay",
+			Description: "Keep playing related songs when the queue is empty",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "whether autoplay is enabled",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "always-on",
+			Description: "Never leave the voice channel because of inactivity",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "whether 24/7 mode is enabled",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "max-queue",
+			Description: "Set maximum queue length",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "length",
+					Description: "0 is unlimited",
+					Required:    true,
+					MinValue:    &minZero,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "sources",
+			Description: "Set allowed song sources",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        types.SourceSearch,
+					Description: "song search by name",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        types.SourceLink,
+					Description: "video links",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        types.SourcePlaylist,
+					Description: "playlist links",
+					Required:    true,
+				},
+			},
+		},
+	},
+}
+
+//settingsCmd is the handler for settings command group.
+func (b *Bot) settingsCmd(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	set, err := b.store.Settings(i.GuildID)
+	if err != nil {
+		b.replyError(s, i, msgInternalErr)
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", i.GuildID)
+		return
+	}
+
+	sub := i.ApplicationCommandData().Options[0]
+	opts := optionMap(sub.Options)
+	switch sub.Name {
+	case "show":
+		b.replyEmbed(s, i, types.SettingsEmbed(b.printer(i), set))
+		return
+	case "volume":
+		set.Volume = int(opts["percent"].IntValue())
+	case "dj-role":
+		set.DJRole = ""
+		if opt, ok := opts["role"]; ok {
+			set.DJRole = opt.RoleValue(nil, i.GuildID).ID
+		}
+	case "prefix":
+		set.Prefix = ""
+		if opt, ok := opts["prefix"]; ok {
+			set.Prefix = strings.TrimSpace(opt.StringValue())
+		}
+	case "language":
+		set.Locale = ""
+		if opt, ok := opts["language"]; ok {
+			if !b.knownLocale(opt.StringValue()) {
+				b.replyError(s, i, "unknown_language", opt.StringValue())
+				return
+			}
+			set.Locale = opt.StringValue()
+		}
+	case "announce-channel":
+		set.AnnounceChannel = ""
+		if opt, ok := opts["channel"]; ok {
+			set.AnnounceChannel = opt.ChannelValue(nil).ID
+		}
+	case "summary-channel":
+		set.SummaryChannel = ""
+		if opt, ok := opts["channel"]; ok {
+			set.SummaryChannel = opt.ChannelValue(nil).ID
+			//First recap is posted a week after it was enabled
+			err = b.store.SetLastSummary(i.GuildID, time.Now())
+			if err != nil {
+				b.log.Errorw(fmt.Sprintf("summary: %s", err.Error()), "guildID", i.GuildID)
+			}
+		}
+	case "loop":
+		set.Loop = opts["mode"].StringValue()
+	case "autoplay":
+		set.Autoplay = opts["enabled"].BoolValue()
+	case "always-on":
+		set.AlwaysOn = opts["enabled"].BoolValue()
+	case "max-queue":
+		set.MaxQueue = int(opts["length"].IntValue())
+	case "sources":
+		set.Sources = nil
+		for _, src := range []string{types.SourceSearch, types.SourceLink, types.SourcePlaylist} {
+			if opts[src].BoolValue() {
+				set.Sources = append(set.Sources, src)
+			}
+		}
+		//Everything allowed is the same as no restrictions
+		if len(set.Sources) == 3 {
+			set.Sources = nil
+		}
+	}
+
+	err = b.store.SaveSettings(i.GuildID, set)
+	if err != nil {
+		b.replyError(s, i, msgInternalErr)
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", i.GuildID)
+		return
+	}
+	b.replyEmbed(s, i, types.SettingsEmbed(b.printer(i), set))
+}
+
+//requireAdmin wraps the handler allowing only server managers to use it.
+func (b *Bot) requireAdmin(h func(s *discordgo.Session, i *discordgo.InteractionCreate)) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		if !isAdmin(i.Member) {
+			b.replyError(s, i, msgNoPermission)
+			return
+		}
+		h(s, i)
+	}
+}
+
+//requireDJ wraps the handler allowing only members with DJ role to use it.
+//Everyone is allowed if DJ role isn't set.
+func (b *Bot) requireDJ(h func(s *discordgo.Session, i *discordgo.InteractionCreate)) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		set, err := b.store.Settings(i.GuildID)
+		if err != nil {
+			b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", i.GuildID)
+		}
+
+		if !isDJ(i.Member, set.DJRole) {
+			b.replyError(s, i, msgNoPermission)
+			return
+		}
+		h(s, i)
+	}
+}
+
+//isAdmin checks whether member can manage the server.
+func isAdmin(m *discordgo.Member) bool {
+	if m == nil {
+		return false
+	}
+	return m.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageGuild) != 0
+}
+
+//isDJ checks whether member is allowed to control playback.
+func isDJ(m *discordgo.Member, djRole string) bool {
+	if djRole == "" || isAdmin(m) {
+		return true
+	}
+	if m == nil {
+		return false
+	}
+	for _, role := range m.Roles {
+		if role == djRole {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//shutdownTimeout is how long players are waited to stop,
+//docker stop kills the container 10 seconds after SIGTERM
+const shutdownTimeout = 7 * time.Second
+
+//shutdown notifies guilds with active players, saves their queues,
+//stops the players, kills child processes and closes the gateway, in that order.
+//HTTP server is shut down meanwhile, requests are waited under the same deadline.
+func (b *Bot) shutdown() {
+	players := b.dispatcher.Players()
+	for _, st := range players {
+		b.notifyShutdown(st)
+	}
+	b.dispatcher.Persist()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	httpDone := make(chan struct{})
+	go func() {
+		defer close(httpDone)
+		if b.http == nil {
+			return
+		}
+		if err := b.http.Shutdown(ctx); err != nil {
+			b.log.Errorw(fmt.Sprintf("shutdown http: %s", err.Error()), "addr", b.http.Addr)
+		}
+	}()
+
+	if err := b.dispatcher.Shutdown(ctx); err != nil {
+		b.log.Errorw(fmt.Sprintf("shutdown: %s", err.Error()), "players", len(players))
+	}
+	//Downloads of players which haven't stopped and of HTTP requests are killed too
+	b.extractor.Close()
+	<-httpDone
+
+	if err := b.s.Close(); err != nil {
+		b.log.Errorw(fmt.Sprintf("close gateway: %s", err.Error()))
+	}
+}
+
+//notifyShutdown tells members of the guild that playback stops and the queue is saved.
+func (b *Bot) notifyShutdown(st types.PlayerState) {
+	t := b.guildPrinter(st.GuildID, "")
+	_, err := b.s.ChannelMessageSend(b.announceChannel(st.GuildID, st.TextID), t("shutting_down"))
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("notify shutdown: %s", err.Error()), "guildID", st.GuildID)
+	}
+}
@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+	"github.com/relipocere/gotune/internal/stats"
+)
+
+const (
+	week = 7 * 24 * time.Hour
+	//recapCheckInterval is how often guilds are checked for due weekly recaps
+	recapCheckInterval = time.Hour
+)
+
+//statsCommand is the definition of the stats command.
+var statsCommand = &discordgo.ApplicationCommand{
+	Name:        "stats",
+	Description: "Show listening statistics of the server",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "period",
+			Description: "period of the statistics, defaults to all time",
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "week", Value: "week"},
+				{Name: "month", Value: "month"},
+				{Name: "all time", Value: "all"},
+			},
+		},
+	},
+}
+
+//statsCmd is the handler for stats command.
+func (b *Bot) statsCmd(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var since time.Time
+	if opt, ok := optionMap(i.ApplicationCommandData().Options)["period"]; ok {
+		switch opt.StringValue() {
+		case "week":
+			since = time.Now().Add(-week)
+		case "month":
+			since = time.Now().AddDate(0, -1, 0)
+		}
+	}
+
+	entries, err := b.store.HistorySince(i.GuildID, since)
+	if err != nil {
+		b.replyError(s, i, msgInternalErr)
+		b.log.Errorw(fmt.Sprintf("history: %s", err.Error()), "guildID", i.GuildID)
+		return
+	}
+
+	t := b.printer(i)
+	b.replyEmbed(s, i, types.StatsEmbed(t, t("stats_title"), stats.Compute(entries, since)))
+}
+
+//weeklyRecaps posts weekly recaps to the guilds that enabled them until done is closed.
+func (b *Bot) weeklyRecaps(done <-chan struct{}) {
+	ticker := time.NewTicker(recapCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, g := range b.Guilds() {
+				b.postRecap(g.ID)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+//postRecap posts recap of the last week to the guild, if it's due.
+func (b *Bot) postRecap(gID string) {
+	set, err := b.store.Settings(gID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", gID)
+		return
+	}
+	if set.SummaryChannel == "" {
+		return
+	}
+
+	last, err := b.store.LastSummary(gID)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("summary: %s", err.Error()), "guildID", gID)
+		return
+	}
+	if time.Since(last) < week {
+		return
+	}
+
+	since := time.Now().Add(-week)
+	entries, err := b.store.HistorySince(gID, since)
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("history: %s", err.Error()), "guildID", gID)
+		return
+	}
+
+	t := b.guildPrinter(gID, "")
+	_, err = b.s.ChannelMessageSendEmbed(set.SummaryChannel, types.StatsEmbed(t, t("recap_title"), stats.Compute(entries, since)))
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("summary: %s", err.Error()), "guildID", gID)
+		return
+	}
+
+	err = b.store.SetLastSummary(gID, time.Now())
+	if err != nil {
+		b.log.Errorw(fmt.Sprintf("summary: %s", err.Error()), "guildID", gID)
+	}
+}
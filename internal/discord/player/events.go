@@ -0,0 +1,170 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//bus delivers events of the guild players to the subscribers.
+type bus struct {
+	mux  *sync.RWMutex
+	subs map[int]*subscription
+	next int
+	//closed is set once the bus is closed, events aren't delivered anymore
+	closed bool
+}
+
+func newBus() *bus {
+	return &bus{
+		mux:  &sync.RWMutex{},
+		subs: make(map[int]*subscription),
+	}
+}
+
+//Subscribe starts delivering events to the handler in its own goroutine,
+//so slow handlers don't hold the players. Returned function cancels the subscription.
+func (b *bus) Subscribe(h func(types.Event)) func() {
+	sub := &subscription{
+		mux:      &sync.Mutex{},
+		signal:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		drain:    make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+
+	b.mux.Lock()
+	if b.closed {
+		b.mux.Unlock()
+		return func() {}
+	}
+	go sub.run(h)
+	id := b.next
+	b.next++
+	b.subs[id] = sub
+	b.mux.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mux.Lock()
+			delete(b.subs, id)
+			b.mux.Unlock()
+			close(sub.done)
+		})
+	}
+}
+
+//Publish queues the event for every subscriber without blocking.
+func (b *bus) Publish(e types.Event) {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	for _, sub := range b.subs {
+		sub.push(e)
+	}
+}
+
+//Close stops accepting events and waits until subscribers handle the pending ones.
+//Error is returned if they aren't handled before the context is done.
+func (b *bus) Close(ctx context.Context) error {
+	b.mux.Lock()
+	if b.closed {
+		b.mux.Unlock()
+		return nil
+	}
+	b.closed = true
+	subs := b.subs
+	b.subs = make(map[int]*subscription)
+	b.mux.Unlock()
+
+	for _, sub := range subs {
+		close(sub.drain)
+	}
+	for _, sub := range subs {
+		select {
+		case <-sub.finished:
+		case <-ctx.Done():
+			return fmt.Errorf("events aren't handled: %w", ctx.Err())
+		}
+	}
+	return nil
+}
+
+//subscription is the queue of events waiting to be handled by the subscriber.
+type subscription struct {
+	mux     *sync.Mutex
+	pending []types.Event
+	//signal wakes up the handler goroutine when events are pushed
+	signal chan struct{}
+	//done cancels the subscription, pending events are dropped
+	done chan struct{}
+	//drain stops the subscription once pending events are handled
+	drain chan struct{}
+	//finished is closed when the handler goroutine returns
+	finished chan struct{}
+}
+
+func (s *subscription) push(e types.Event) {
+	s.mux.Lock()
+	s.pending = append(s.pending, e)
+	s.mux.Unlock()
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+//run handles the events in the order they were published until the subscription is cancelled or drained.
+func (s *subscription) run(h func(types.Event)) {
+	defer close(s.finished)
+	for {
+		select {
+		case <-s.signal:
+		case <-s.done:
+			return
+		case <-s.drain:
+			s.handle(h)
+			return
+		}
+		s.handle(h)
+	}
+}
+
+//handle calls the handler with the pending events.
+func (s *subscription) handle(h func(types.Event)) {
+	s.mux.Lock()
+	events := s.pending
+	s.pending = nil
+	s.mux.Unlock()
+
+	for _, e := range events {
+		h(e)
+	}
+}
+
+//Subscribe calls the handler with every event of the guild players in the order they're published.
+//Handlers are called in their own goroutine, returned function cancels the subscription.
+func (d *Dispatcher) Subscribe(h func(types.Event)) (unsubscribe func()) {
+	return d.events.Subscribe(h)
+}
+
+//publish sends the event about the guild player to the subscribers.
+func (d *Dispatcher) publish(gID string, p *player, e types.Event) {
+	e.GuildID = gID
+	e.VoiceID = p.VoiceID()
+	e.TextID = p.TextID()
+	e.State = p.State().String()
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	d.events.Publish(e)
+}
+
+//queueChanged publishes the current queue of the guild player.
+func (d *Dispatcher) queueChanged(gID string, p *player) {
+	d.publish(gID, p, types.Event{Type: types.EventQueueChanged, Queue: p.Queue.ListSongs()})
+}
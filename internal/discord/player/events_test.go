@@ -0,0 +1,152 @@
+package player
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//eventTimeout is how long tests wait for events to be delivered.
+const eventTimeout = time.Second
+
+//recorder collects the guild IDs of the handled events.
+type recorder struct {
+	mux    *sync.Mutex
+	guilds []string
+	//received gets the guild ID of every handled event
+	received chan string
+}
+
+func newRecorder() *recorder {
+	return &recorder{mux: &sync.Mutex{}, received: make(chan string, 100)}
+}
+
+func (r *recorder) handle(e types.Event) {
+	r.mux.Lock()
+	r.guilds = append(r.guilds, e.GuildID)
+	r.mux.Unlock()
+	r.received <- e.GuildID
+}
+
+func (r *recorder) handled() []string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return append([]string(nil), r.guilds...)
+}
+
+//wait waits until n events are handled.
+func (r *recorder) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.received:
+		case <-time.After(eventTimeout):
+			t.Fatalf("%d of %d events were handled", i, n)
+		}
+	}
+}
+
+func TestBusOrder(t *testing.T) {
+	b := newBus()
+	first, second := newRecorder(), newRecorder()
+	b.Subscribe(first.handle)
+	b.Subscribe(second.handle)
+
+	var want []string
+	for n := 0; n < 50; n++ {
+		id := string(rune('a' + n%26))
+		want = append(want, id)
+		b.Publish(types.Event{GuildID: id})
+	}
+
+	for _, r := range []*recorder{first, second} {
+		r.wait(t, len(want))
+		if got := r.handled(); !reflect.DeepEqual(got, want) {
+			t.Errorf("events are handled in order %v, want %v", got, want)
+		}
+	}
+}
+
+func TestBusSlowSubscriber(t *testing.T) {
+	b := newBus()
+	release := make(chan struct{})
+	defer close(release)
+	b.Subscribe(func(types.Event) {
+		<-release
+	})
+	fast := newRecorder()
+	b.Subscribe(fast.handle)
+
+	published := make(chan struct{})
+	go func() {
+		for n := 0; n < 10; n++ {
+			b.Publish(types.Event{GuildID: "g"})
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(eventTimeout):
+		t.Fatal("publishing is blocked by the slow subscriber")
+	}
+	fast.wait(t, 10)
+}
+
+func TestBusUnsubscribe(t *testing.T) {
+	b := newBus()
+	r := newRecorder()
+	unsubscribe := b.Subscribe(r.handle)
+	b.Publish(types.Event{GuildID: "before"})
+	r.wait(t, 1)
+
+	unsubscribe()
+	unsubscribe()
+	b.Publish(types.Event{GuildID: "after"})
+	select {
+	case id := <-r.received:
+		t.Errorf("event %q is handled after unsubscribing", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBusClose(t *testing.T) {
+	b := newBus()
+	release := make(chan struct{})
+	r := newRecorder()
+	b.Subscribe(func(e types.Event) {
+		<-release
+		r.handle(e)
+	})
+	for _, id := range []string{"a", "b", "c"} {
+		b.Publish(types.Event{GuildID: id})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Close(ctx); err == nil {
+		t.Error("Close() must fail while events are being handled")
+	}
+
+	close(release)
+	if err := b.Close(context.Background()); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	r.wait(t, 3)
+	if got := r.handled(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("pending events handled on close = %v", got)
+	}
+
+	b.Publish(types.Event{GuildID: "late"})
+	b.Subscribe(r.handle)
+	b.Publish(types.Event{GuildID: "late"})
+	select {
+	case id := <-r.received:
+		t.Errorf("event %q is handled after closing", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
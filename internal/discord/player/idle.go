@@ -0,0 +1,182 @@
+package player
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//listenersCheckInterval is how often the player checks whether it's alone in the channel.
+const listenersCheckInterval = 5 * time.Second
+
+//IdleOptions configure when the player leaves the voice channel.
+type IdleOptions struct {
+	//Idle is how long player waits for new songs after the queue is empty,
+	//0 means it leaves immediately
+	Idle time.Duration
+	//Alone is how long player stays alone in the channel, 0 means forever
+	Alone time.Duration
+	//PauseWhenAlone pauses playback while nobody is listening
+	PauseWhenAlone bool
+}
+
+//waitForSongs waits until songs are added to the empty queue.
+//False is returned if player should leave because of idle timeout or stop command.
+//Players of the guilds in 24/7 mode wait forever.
+func (d *Dispatcher) waitForSongs(gID string, p *player) bool {
+	var timeout <-chan time.Time
+	if !d.guildSettings(gID).AlwaysOn {
+		if d.idle.Idle <= 0 {
+			return false
+		}
+		timer := time.NewTimer(d.idle.Idle)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	d.log.Debugw("waiting for songs", "guildID", gID)
+	d.transition(gID, p, StateIdle)
+	for {
+		select {
+		case <-p.Added:
+			//Signal may be left from songs that were already played
+			if p.Queue.Len() > 0 {
+				d.transition(gID, p, StateLoading)
+				return true
+			}
+		case cmd := <-p.Command:
+			if cmd.action == actionStop {
+				d.transition(gID, p, StateStopping)
+				cmd.respond(types.Song{}, nil)
+				return false
+			}
+			cmd.respond(types.Song{}, types.ErrNotPlaying)
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+//await runs the loading step in a goroutine, handling commands of the player until it's done.
+//Songs can't be controlled while loading, only stop is accepted.
+//False is returned if the player is stopped meanwhile, then the result of the step is abandoned
+//and abandon is called with it once the step is done.
+func (d *Dispatcher) await(gID string, p *player, step, abandon func()) bool {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		step()
+	}()
+
+	for {
+		select {
+		case <-done:
+			return true
+		case cmd := <-p.Command:
+			if cmd.action != actionStop {
+				cmd.respond(types.Song{}, types.ErrNotPlaying)
+				continue
+			}
+			d.transition(gID, p, StateStopping)
+			cmd.respond(types.Song{}, nil)
+			if abandon != nil {
+				go func() {
+					<-done
+					abandon()
+				}()
+			}
+			return false
+		}
+	}
+}
+
+//watchListeners pauses the player when nobody is listening
+//and stops it when it's alone for too long, until done is closed.
+func (d *Dispatcher) watchListeners(gID string, p *player, done <-chan struct{}) {
+	ticker := time.NewTicker(listenersCheckInterval)
+	defer ticker.Stop()
+
+	var aloneSince time.Time
+	//pausedAlone is set once the song is paused in the current alone period,
+	//so songs resumed by the user aren't paused again
+	var pausedAlone bool
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+
+		g, err := d.s.State.Guild(gID)
+		if err != nil {
+			continue
+		}
+
+		if Listeners(g, p.VoiceID(), d.s.State.User.ID) > 0 {
+			aloneSince = time.Time{}
+			pausedAlone = false
+			//Songs paused or resumed by the user in the meantime aren't resumed
+			if p.AutoPaused() {
+				d.send(p, newCommand(actionResume))
+			}
+			continue
+		}
+
+		if aloneSince.IsZero() {
+			aloneSince = time.Now()
+		}
+
+		if d.idle.PauseWhenAlone && !pausedAlone && p.State() == StatePlaying {
+			cmd := newCommand(actionPause)
+			cmd.auto = true
+			pausedAlone = d.send(p, cmd).err == nil
+		}
+
+		if d.idle.Alone > 0 && time.Since(aloneSince) >= d.idle.Alone && !d.guildSettings(gID).AlwaysOn {
+			d.log.Debugw("leaving empty channel", "guildID", gID)
+			if d.send(p, newCommand(actionStop)).err == nil {
+				return
+			}
+		}
+	}
+}
+
+//send sends the command to the player and waits for the result.
+//Players which are leaving don't accept commands anymore.
+func (d *Dispatcher) send(p *player, cmd command) result {
+	if p.State() == StateStopping {
+		return result{err: types.ErrNoPlayer}
+	}
+
+	timer := time.NewTimer(commandTimeout)
+	defer timer.Stop()
+	select {
+	case p.Command <- cmd:
+	case <-timer.C:
+		return result{err: fmt.Errorf("%s: %w", cmd.action, types.ErrPlayerUnresponsive)}
+	}
+
+	select {
+	case res := <-cmd.reply:
+		return res
+	case <-timer.C:
+		return result{err: fmt.Errorf("%s: %w", cmd.action, types.ErrPlayerUnresponsive)}
+	}
+}
+
+//Listeners counts users other than bots in the voice channel of the guild.
+func Listeners(g *discordgo.Guild, vID, botID string) int {
+	var n int
+	for _, vs := range g.VoiceStates {
+		if vs.ChannelID != vID || vs.UserID == botID {
+			continue
+		}
+		if vs.Member != nil && vs.Member.User != nil && vs.Member.User.Bot {
+			continue
+		}
+		n++
+	}
+	return n
+}
@@ -2,29 +2,204 @@ package player
 
 import (
 	"sync"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
 )
 
 //player represent guild player.
 type player struct {
 	Command chan command
 	Queue   *queue
+	//Added signals that songs were added to the queue
+	Added chan struct{}
+
+	mux *sync.RWMutex
+	//voiceID is the voice channel player is expected to be in
+	voiceID string
+	//textID is the text channel player was requested from
+	textID string
+	//current is the song being played
+	current *types.Song
+	//lastPlayed is the song played before the current one
+	lastPlayed *types.Song
+	//position is the playback time of the current song
+	position time.Duration
+	//startAt is the time in seconds the first song starts playing from
+	startAt int
+	state   State
+	//autoPaused is set while the song is paused because nobody is listening
+	autoPaused bool
+	//encoder is the ffmpeg session of the current song
+	encoder encoder
+}
+
+//newPlayer returns player bound to the voice and text channels.
+func newPlayer(vID, textID string) *player {
+	return &player{
+		Command: make(chan command),
+		Queue:   newQueue(),
+		Added:   make(chan struct{}, 1),
+		mux:     &sync.RWMutex{},
+		voiceID: vID,
+		textID:  textID,
+	}
+}
+
+//VoiceID returns voice channel the player is expected to be in.
+func (p *player) VoiceID() string {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.voiceID
+}
+
+//SetVoiceID changes voice channel the player is expected to be in.
+func (p *player) SetVoiceID(vID string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.voiceID = vID
+}
+
+//TextID returns text channel the player was requested from.
+func (p *player) TextID() string {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.textID
+}
+
+//SetCurrent sets the song being played and its starting position.
+//Finished song is remembered as the last played one.
+func (p *player) SetCurrent(song *types.Song, position time.Duration) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if song == nil && p.current != nil {
+		p.lastPlayed = p.current
+	}
+	p.current = song
+	p.position = position
+}
+
+//Current returns the song being played and its playback time.
+func (p *player) Current() (types.Song, time.Duration, bool) {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	if p.current == nil {
+		return types.Song{}, 0, false
+	}
+	return *p.current, p.position, true
+}
+
+//LastPlayed returns the song played before the current one.
+func (p *player) LastPlayed() (types.Song, bool) {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	if p.lastPlayed == nil {
+		return types.Song{}, false
+	}
+	return *p.lastPlayed, true
+}
+
+//SetPosition sets the playback time of the current song.
+func (p *player) SetPosition(position time.Duration) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.position = position
+}
+
+//State returns the stage of the player lifecycle.
+func (p *player) State() State {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.state
+}
+
+//setState changes the stage of the player lifecycle, the previous one is returned.
+//Leaving the paused state clears the automatic pause.
+func (p *player) setState(st State) State {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	prev := p.state
+	p.state = st
+	if st != StatePaused {
+		p.autoPaused = false
+	}
+	return prev
+}
+
+//AutoPaused reports whether the song is paused because nobody is listening.
+func (p *player) AutoPaused() bool {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.autoPaused
+}
+
+//SetAutoPaused marks the pause as automatic or as requested by the user.
+func (p *player) SetAutoPaused(auto bool) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.autoPaused = auto
+}
+
+//SetEncoder sets the ffmpeg session of the current song, nil once the song ends.
+func (p *player) SetEncoder(e encoder) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.encoder = e
+}
+
+//KillEncoder kills ffmpeg process of the current song, so its playback ends.
+func (p *player) KillEncoder() {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	if p.encoder != nil {
+		p.encoder.Stop()
+	}
+}
+
+//TakeStartAt returns starting time of the song and resets it,
+//so only the first song is affected.
+func (p *player) TakeStartAt() int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	startAt := p.startAt
+	p.startAt = 0
+	return startAt
+}
+
+//Snapshot returns snapshot of the player.
+func (p *player) Snapshot(gID string) types.PlayerState {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	st := types.PlayerState{
+		GuildID: gID,
+		VoiceID: p.voiceID,
+		TextID:  p.textID,
+		Offset:  int(p.position / time.Second),
+		Queue:   p.Queue.ListSongs(),
+	}
+	if p.current != nil {
+		cur := *p.current
+		st.Current = &cur
+	}
+	return st
 }
 
 //playerMap is map safe for concurrent use.
 type playerMap struct {
 	mux      *sync.RWMutex
-	internal map[string]player
+	internal map[string]*player
 }
 
 func newPlayerMap() *playerMap {
 	return &playerMap{
 		mux:      &sync.RWMutex{},
-		internal: make(map[string]player),
+		internal: make(map[string]*player),
 	}
 }
 
 //Load gets player from the map.
-func (pm *playerMap) Load(key string) (value player, ok bool) {
+func (pm *playerMap) Load(key string) (value *player, ok bool) {
 	pm.mux.RLock()
 	p, ok := pm.internal[key]
 	pm.mux.RUnlock()
@@ -38,8 +213,22 @@ func (pm *playerMap) Delete(key string) {
 	pm.mux.Unlock()
 }
 
+//Range calls f for each player in the map.
+func (pm *playerMap) Range(f func(key string, value *player)) {
+	pm.mux.RLock()
+	players := make(map[string]*player, len(pm.internal))
+	for k, v := range pm.internal {
+		players[k] = v
+	}
+	pm.mux.RUnlock()
+
+	for k, v := range players {
+		f(k, v)
+	}
+}
+
 //Store adds player to the map.
-func (pm *playerMap) Store(key string, value player) {
+func (pm *playerMap) Store(key string, value *player) {
 	pm.mux.Lock()
 	pm.internal[key] = value
 	pm.mux.Unlock()
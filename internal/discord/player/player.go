@@ -3,62 +3,204 @@ package player
 import (
 	"fmt"
 	"io"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jonas747/dca"
 	"github.com/relipocere/gotune/internal/discord/types"
+	"github.com/relipocere/gotune/internal/metrics"
 	"go.uber.org/zap"
 )
 
 const (
-	msgNoPlayer            = "Bot is not playing"
-	msgUnexpectedError     = "Unexpected error"
-	msgDone                = "👍"
-	errPlayerNotResponding = "player is not reading command"
+	//commandTimeout is how long the player is waited to handle the command
+	commandTimeout = 5 * time.Second
+	//frameTimeout is how long the voice connection is waited to accept the frame
+	frameTimeout = 5 * time.Second
+	//connectionCheckInterval is how often the voice connection is checked to be ready
+	connectionCheckInterval = time.Second
+	//checkpointInterval is how often playback position is saved
+	checkpointInterval = 10 * time.Second
+	//autoplaySeeds is the number of last played songs related songs are based on
+	autoplaySeeds = 3
+	//autoplayRecent is the number of last played songs autoplay doesn't repeat
+	autoplayRecent = 50
 )
 
 //Dispatcher is the player manager that routes songs to the correct guild player.
 type Dispatcher struct {
-	s       *discordgo.Session
-	log     *zap.SugaredLogger
-	players *playerMap
+	s         *discordgo.Session
+	log       *zap.SugaredLogger
+	store     types.Store
+	extractor types.Extractor
+	idle      IdleOptions
+	players   *playerMap
+	//closing is set once states are persisted for shutdown
+	closing atomic.Bool
+	//events are delivered to the subscribers
+	events *bus
+	//running tracks player goroutines, so shutdown can wait for them
+	running *sync.WaitGroup
+	//join connects to the voice channel, encode starts encoding of the song
+	join   func(gID, vID string) (voice, error)
+	encode func(path string, opts *dca.EncodeOptions) (encoder, error)
 }
 
-//command is the message type for player.command channel.
-type command struct {
-	//Valid actions are: skip, seek, stop, pause, resume
-	Action string
+//outcome is how the song playback ended.
+type outcome int
 
-	//Seek time in seconds
-	SeekTime int
-}
+const (
+	outcomeFinished outcome = iota
+	outcomeSkipped
+	outcomeStopped
+)
 
 //NewDispatcher creates new player dispatcher.
-func NewDispatcher(s *discordgo.Session, log *zap.SugaredLogger) *Dispatcher {
-	return &Dispatcher{
-		s:       s,
-		log:     log,
-		players: newPlayerMap(),
+func NewDispatcher(s *discordgo.Session, log *zap.SugaredLogger, store types.Store, e types.Extractor, idle IdleOptions) *Dispatcher {
+	d := &Dispatcher{
+		s:         s,
+		log:       log,
+		store:     store,
+		extractor: e,
+		idle:      idle,
+		players:   newPlayerMap(),
+		events:    newBus(),
+		running:   &sync.WaitGroup{},
+		encode:    encodeFile,
 	}
+	d.join = d.joinVoice
+	return d
 }
 
 //Play adds songs to the queue of the guild player.
 //If player doesn't exist, new one is created and launched in goroutine.
-func (d *Dispatcher) Play(gID, vID, cmdID string, songs []types.Song) {
+func (d *Dispatcher) Play(gID, vID, cmdID string, songs []types.Song) error {
+	return d.enqueue(gID, vID, cmdID, songs, false)
+}
+
+//PlayNext adds songs to the beginning of the queue of the guild player.
+//If player doesn't exist, new one is created and launched in goroutine.
+func (d *Dispatcher) PlayNext(gID, vID, cmdID string, songs []types.Song) error {
+	return d.enqueue(gID, vID, cmdID, songs, true)
+}
+
+//enqueue adds songs to the guild player, launching it if needed.
+//Songs aren't accepted once the queues are persisted during shutdown, they would be lost.
+func (d *Dispatcher) enqueue(gID, vID, cmdID string, songs []types.Song, front bool) error {
+	if d.closing.Load() {
+		return types.ErrShuttingDown
+	}
 	p, exists := d.players.Load(gID)
 	if !exists {
-		p = player{
-			Command: make(chan command),
-			Queue:   newQueue(),
-		}
+		p = newPlayer(vID, cmdID)
 		d.players.Store(gID, p)
+		d.publish(gID, p, types.Event{Type: types.EventPlayerCreated})
 	}
 
-	p.Queue.Push(songs)
+	if front {
+		p.Queue.PushFront(songs)
+	} else {
+		p.Queue.Push(songs)
+	}
+	select {
+	case p.Added <- struct{}{}:
+	default:
+	}
+	d.saveState(gID, p)
+	d.queueChanged(gID, p)
 	if !exists {
-		go d.dispatchPlayer(gID, vID, cmdID)
+		d.launch(gID, cmdID)
+	}
+	return nil
+}
+
+//Restore launches guild player from the saved state.
+//Current song is resumed from the saved offset.
+func (d *Dispatcher) Restore(st types.PlayerState) {
+	if _, exists := d.players.Load(st.GuildID); exists || d.closing.Load() {
+		return
+	}
+
+	p := newPlayer(st.VoiceID, st.TextID)
+	if st.Current != nil {
+		p.Queue.Push([]types.Song{*st.Current})
+		p.startAt = st.Offset
+	}
+	p.Queue.Push(st.Queue)
+	d.players.Store(st.GuildID, p)
+	d.publish(st.GuildID, p, types.Event{Type: types.EventPlayerCreated})
+
+	d.launch(st.GuildID, st.TextID)
+}
+
+//launch runs the guild player in goroutine.
+func (d *Dispatcher) launch(gID, cmdID string) {
+	d.running.Add(1)
+	go func() {
+		defer d.running.Done()
+		d.dispatchPlayer(gID, cmdID)
+	}()
+}
+
+//Persist saves states of all guild players.
+//States are no longer updated afterwards, so they can be restored after restart.
+func (d *Dispatcher) Persist() {
+	d.players.Range(func(gID string, p *player) {
+		d.saveState(gID, p)
+	})
+	d.closing.Store(true)
+}
+
+//Join moves guild player to another voice channel keeping the queue.
+func (d *Dispatcher) Join(gID, vID string) error {
+	p, ok := d.players.Load(gID)
+	if !ok {
+		return types.ErrNoPlayer
+	}
+
+	if p.VoiceID() == vID {
+		return types.ErrAlreadyInChannel
+	}
+
+	d.s.RLock()
+	vc, ok := d.s.VoiceConnections[gID]
+	d.s.RUnlock()
+	if !ok {
+		return &types.JoinError{VoiceID: vID, Err: fmt.Errorf("no voice connection for the guild")}
+	}
+
+	//Voice channel must be updated before the move,
+	//otherwise voice state update is treated as forceful move
+	prevID := p.VoiceID()
+	p.SetVoiceID(vID)
+	err := vc.ChangeChannel(vID, false, true)
+	if err != nil {
+		p.SetVoiceID(prevID)
+		return &types.JoinError{VoiceID: vID, Err: err}
 	}
+
+	return nil
+}
+
+//VoiceChannel returns voice channel guild player is expected to be in.
+//Empty string is returned if there is no player in the guild.
+func (d *Dispatcher) VoiceChannel(gID string) string {
+	p, ok := d.players.Load(gID)
+	if !ok {
+		return ""
+	}
+	return p.VoiceID()
+}
+
+//TextChannel returns text channel the guild player was requested from, empty if there is no player.
+func (d *Dispatcher) TextChannel(gID string) string {
+	p, ok := d.players.Load(gID)
+	if !ok {
+		return ""
+	}
+	return p.TextID()
 }
 
 //Queue returns titles of queued songs.
@@ -71,194 +213,428 @@ func (d *Dispatcher) Queue(gID string) []types.Song {
 	return p.Queue.ListSongs()
 }
 
-//Seek skips song playback to the desired time.
-//seekTime is measured in seconds.
-func (d *Dispatcher) Seek(gID string, seekTime int) (string, error) {
+//NowPlaying returns the song being played and its playback time.
+func (d *Dispatcher) NowPlaying(gID string) (types.Song, time.Duration, bool) {
 	p, ok := d.players.Load(gID)
 	if !ok {
-		return msgNoPlayer, nil
-	}
-
-	select {
-	case p.Command <- command{Action: "seek", SeekTime: seekTime}:
-	case <-time.After(5 * time.Second):
-		return msgUnexpectedError, fmt.Errorf(errPlayerNotResponding)
+		return types.Song{}, 0, false
 	}
+	return p.Current()
+}
 
-	return msgDone, nil
+//Seek skips song playback to the desired time.
+//seekTime is measured in seconds.
+func (d *Dispatcher) Seek(gID string, seekTime int) error {
+	return d.sendTo(gID, newSeekCommand(time.Duration(seekTime)*time.Second)).err
 }
 
-//SkipTo skips to the specified queue position.
-func (d *Dispatcher) SkipTo(gID string, pos int) (string, error) {
+//SkipTo skips to the specified queue position, the song which is played next is returned.
+func (d *Dispatcher) SkipTo(gID string, pos int) (types.Song, error) {
 	p, ok := d.players.Load(gID)
 	if !ok {
-		return msgNoPlayer, nil
+		return types.Song{}, types.ErrNoPlayer
 	}
 
 	qLen := p.Queue.Len()
-	if qLen < pos {
-		return fmt.Sprintf("There are only %d songs in the queue", qLen), nil
+	if pos < 1 || qLen < pos {
+		return types.Song{}, &types.QueueRangeError{Pos: pos, Len: qLen}
 	}
 
 	for i := 0; i < pos-1; i++ {
 		p.Queue.Pop()
 	}
+	next := p.Queue.ListSongs()[0]
+	d.saveState(gID, p)
+	d.queueChanged(gID, p)
 
-	return d.Skip(gID)
+	_, err := d.Skip(gID)
+	return next, err
 }
 
-//Stop stops music stream and discards the queue.
-func (d *Dispatcher) Stop(gID string) (string, error) {
+//Remove removes song at the position from the queue.
+func (d *Dispatcher) Remove(gID string, pos int) (types.Song, error) {
 	p, ok := d.players.Load(gID)
 	if !ok {
-		return msgNoPlayer, nil
+		return types.Song{}, types.ErrNoPlayer
 	}
-	select {
-	case p.Command <- command{Action: "stop"}:
-	case <-time.After(5 * time.Second):
-		return msgUnexpectedError, fmt.Errorf(errPlayerNotResponding)
+
+	song, ok := p.Queue.Remove(pos - 1)
+	if !ok {
+		return types.Song{}, &types.QueueRangeError{Pos: pos, Len: p.Queue.Len()}
 	}
+	d.saveState(gID, p)
+	d.queueChanged(gID, p)
 
-	return msgDone, nil
+	return song, nil
 }
 
-//Skip skips currently playing track.
-func (d *Dispatcher) Skip(gID string) (string, error) {
+//Move moves song in the queue from one position to another.
+func (d *Dispatcher) Move(gID string, from, to int) (types.Song, error) {
 	p, ok := d.players.Load(gID)
 	if !ok {
-		return msgNoPlayer, nil
+		return types.Song{}, types.ErrNoPlayer
 	}
-	select {
-	case p.Command <- command{Action: "skip"}:
-	case <-time.After(5 * time.Second):
-		return msgUnexpectedError, fmt.Errorf(errPlayerNotResponding)
+
+	song, ok := p.Queue.Move(from-1, to-1)
+	if !ok {
+		return types.Song{}, &types.QueueRangeError{Pos: max(from, to), Len: p.Queue.Len()}
 	}
+	d.saveState(gID, p)
+	d.queueChanged(gID, p)
 
-	return msgDone, nil
+	return song, nil
 }
 
-//Pause pauses currently playing track.
-func (d *Dispatcher) Pause(gID string) (string, error) {
-	p, ok := d.players.Load(gID)
-	if !ok {
-		return msgNoPlayer, nil
-	}
-	select {
-	case p.Command <- command{Action: "pause"}:
-	case <-time.After(5 * time.Second):
-		return msgUnexpectedError, fmt.Errorf(errPlayerNotResponding)
-	}
+//Stop stops music stream and discards the queue.
+func (d *Dispatcher) Stop(gID string) error {
+	return d.sendTo(gID, newCommand(actionStop)).err
+}
 
-	return msgDone, nil
+//Skip skips currently playing track, the skipped song is returned.
+func (d *Dispatcher) Skip(gID string) (types.Song, error) {
+	res := d.sendTo(gID, newCommand(actionSkip))
+	return res.song, res.err
+}
+
+//Pause pauses currently playing track.
+func (d *Dispatcher) Pause(gID string) error {
+	return d.sendTo(gID, newCommand(actionPause)).err
 }
 
 //Resume resumes track that was playing.
-func (d *Dispatcher) Resume(gID string) (string, error) {
+func (d *Dispatcher) Resume(gID string) error {
+	return d.sendTo(gID, newCommand(actionResume)).err
+}
+
+//sendTo sends the command to the guild player and waits for the result.
+func (d *Dispatcher) sendTo(gID string, cmd command) result {
 	p, ok := d.players.Load(gID)
 	if !ok {
-		return msgNoPlayer, nil
-	}
-	select {
-	case p.Command <- command{Action: "resume"}:
-	case <-time.After(5 * time.Second):
-		return msgUnexpectedError, fmt.Errorf(errPlayerNotResponding)
+		return result{err: types.ErrNoPlayer}
 	}
-
-	return msgDone, nil
+	return d.send(p, cmd)
 }
 
 //dispatchPlayer creates new player for the guild.
-func (d *Dispatcher) dispatchPlayer(gID, vID, cmdID string) {
+func (d *Dispatcher) dispatchPlayer(gID, cmdID string) {
 	p, _ := d.players.Load(gID)
 	defer d.players.Delete(gID)
-
-	vc, err := d.s.ChannelVoiceJoin(gID, vID, false, true)
+	defer d.deleteState(gID)
+	d.transition(gID, p, StateLoading)
+
+	//Joining may take a while, the player can be stopped meanwhile
+	vID := p.VoiceID()
+	var vc voice
+	var err error
+	joined := d.await(gID, p, func() {
+		vc, err = d.join(gID, vID)
+	}, func() {
+		if err == nil {
+			vc.Disconnect()
+		}
+	})
+	if !joined {
+		d.publish(gID, p, types.Event{Type: types.EventPlayerDestroyed})
+		return
+	}
 	if err != nil {
-		d.s.ChannelMessageSendEmbed(cmdID, types.ErrorEmbed("Can't join the channel"))
-		d.log.Errorw(err.Error(), "guildID", gID, "voiceID", vID)
+		err = &types.JoinError{VoiceID: vID, Err: err}
+		d.transition(gID, p, StateStopping)
+		d.publish(gID, p, types.Event{Type: types.EventPlayerDestroyed, Err: err})
+		d.log.Errorw(err.Error(), "guildID", gID)
 		return
 	}
+	defer d.publish(gID, p, types.Event{Type: types.EventPlayerDestroyed})
 	defer vc.Disconnect()
+	defer d.transition(gID, p, StateStopping)
+
+	done := make(chan struct{})
+	defer close(done)
+	go d.checkpoint(gID, p, done)
+	go d.watchListeners(gID, p, done)
+	go watchConnection(vc, done)
 
 	for {
+		d.transition(gID, p, StateLoading)
 		if p.Queue.Len() < 1 {
-			d.log.Debugw("done playing", "guildID", gID)
-			return
+			//Related song is downloaded in the background, so the player can be stopped meanwhile
+			var related types.Song
+			var found bool
+			if !d.await(gID, p, func() { related, found = d.related(gID, p) }, nil) {
+				return
+			}
+			if found {
+				p.Queue.Push([]types.Song{related})
+				d.queueChanged(gID, p)
+			} else if !d.waitForSongs(gID, p) {
+				d.log.Debugw("done playing", "guildID", gID)
+				return
+			}
 		}
 
 		song := p.Queue.Pop()
-		d.s.ChannelMessageSendEmbed(cmdID, types.TrackEmbed("Now playing", song))
+		startAt := p.TakeStartAt()
+		p.SetCurrent(&song, time.Duration(startAt)*time.Second)
+		d.saveState(gID, p)
+		d.queueChanged(gID, p)
 		d.log.Debugw("playing", "guildID", gID, "song", song)
 
-		stop, err := encodeAndPlay(vc, song.Path, p.Command)
+		startedAt := time.Now()
+		d.publish(gID, p, types.Event{Type: types.EventTrackStarted, Song: &song, StartedAt: startedAt})
+		end, err := d.playSong(gID, vc, p, song, startAt, d.guildSettings(gID).Volume)
+		p.SetCurrent(nil, 0)
 		if err != nil {
-			d.s.ChannelMessageSendEmbed(cmdID, types.ErrorEmbed("Unable to play the song"))
-			d.log.Errorw(fmt.Sprintf("encodeAndPlay: %s", err.Error()), "path", song.Path)
+			d.publish(gID, p, types.Event{Type: types.EventTrackErrored, Song: &song, StartedAt: startedAt, Err: err})
+			d.log.Errorw(fmt.Sprintf("playSong: %s", err.Error()), "path", song.Path)
+			continue
+		}
+
+		ended := types.EventTrackFinished
+		if end != outcomeFinished {
+			ended = types.EventTrackSkipped
+		}
+		d.publish(gID, p, types.Event{Type: ended, Song: &song, StartedAt: startedAt})
+		if end == outcomeStopped {
+			return
+		}
+
+		//Settings may have changed while the song was playing
+		switch d.guildSettings(gID).Loop {
+		case types.LoopTrack:
+			if end != outcomeSkipped {
+				p.Queue.PushFront([]types.Song{song})
+				d.queueChanged(gID, p)
+			}
+		case types.LoopQueue:
+			p.Queue.Push([]types.Song{song})
+			d.queueChanged(gID, p)
+		}
+	}
+}
+
+//checkpoint periodically saves state of the player until done is closed.
+func (d *Dispatcher) checkpoint(gID string, p *player, done <-chan struct{}) {
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.saveState(gID, p)
+		case <-done:
+			return
 		}
-		if stop {
+	}
+}
+
+//watchConnection counts voice connections restored after they were lost until done is closed.
+func watchConnection(vc voice, done <-chan struct{}) {
+	ticker := time.NewTicker(connectionCheckInterval)
+	defer ticker.Stop()
+
+	ready := true
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
 			return
 		}
+
+		now := vc.Connected()
+		if now && !ready {
+			metrics.VoiceReconnects.Inc()
+		}
+		ready = now
 	}
-	d.log.Debugw("Deleting guild player", "guildID", gID)
 }
 
-//encodeAndPlay encodes the file into a dca session and plays it.
-//If stop signal was sent on command channel stop flag will be true.
-func encodeAndPlay(vc *discordgo.VoiceConnection, path string, command <-chan command) (stop bool, rErr error) {
-	opts := dca.StdEncodeOptions
-	opts.StartTime = 0
+//saveState saves state of the guild player.
+func (d *Dispatcher) saveState(gID string, p *player) {
+	if d.closing.Load() {
+		return
+	}
 
-	encodeSession, err := dca.EncodeFile(path, opts)
+	err := d.store.SaveState(p.Snapshot(gID))
 	if err != nil {
-		rErr = err
+		d.log.Errorw(fmt.Sprintf("state: %s", err.Error()), "guildID", gID)
+	}
+}
+
+//deleteState removes saved state of the guild player, once it's done playing.
+func (d *Dispatcher) deleteState(gID string) {
+	if d.closing.Load() {
 		return
 	}
-	defer encodeSession.Cleanup()
+
+	err := d.store.DeleteState(gID)
+	if err != nil {
+		d.log.Errorw(fmt.Sprintf("state: %s", err.Error()), "guildID", gID)
+	}
+}
+
+//related downloads song related to the recently played ones,
+//if autoplay is enabled in the guild.
+func (d *Dispatcher) related(gID string, p *player) (types.Song, bool) {
+	if !d.guildSettings(gID).Autoplay {
+		return types.Song{}, false
+	}
+
+	entries, _, err := d.store.History(gID, 0, autoplayRecent)
+	if err != nil {
+		d.log.Errorw(fmt.Sprintf("history: %s", err.Error()), "guildID", gID)
+		return types.Song{}, false
+	}
+
+	recent := make([]types.Song, 0, len(entries)+1)
+	//History is recorded by the subscriber, so the last song may not be saved yet
+	if last, ok := p.LastPlayed(); ok && (len(entries) < 1 || entries[0].Song.Link != last.Link) {
+		recent = append(recent, last)
+	}
+	for _, e := range entries {
+		recent = append(recent, e.Song)
+	}
+	seeds := recent
+	if len(seeds) > autoplaySeeds {
+		seeds = seeds[:autoplaySeeds]
+	}
+
+	song, err := d.extractor.Related(seeds, recent)
+	if err != nil {
+		d.log.Warnw(fmt.Sprintf("autoplay: %s", err.Error()), "guildID", gID)
+		return types.Song{}, false
+	}
+	return song, true
+}
+
+//GuildLocale returns the preferred locale of the guild, if it's known.
+func GuildLocale(s *discordgo.Session, gID string) string {
+	g, err := s.State.Guild(gID)
+	if err != nil {
+		return ""
+	}
+	return g.PreferredLocale
+}
+
+//guildSettings returns settings of the guild, falling back to defaults on error.
+func (d *Dispatcher) guildSettings(gID string) types.Settings {
+	set, err := d.store.Settings(gID)
+	if err != nil {
+		d.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", gID)
+	}
+	return set
+}
+
+//playSong encodes the file into a dca session and plays it,
+//handling commands of the player until the song ends.
+//Playback starts at startTime seconds, volume is measured in percent.
+func (d *Dispatcher) playSong(gID string, vc voice, p *player, song types.Song, startTime, volume int) (outcome, error) {
+	opts := *dca.StdEncodeOptions
+	opts.StartTime = startTime
+	opts.Volume = dca.StdEncodeOptions.Volume * volume / 100
+
+	encodeSession, err := d.encode(song.Path, &opts)
+	if err != nil {
+		return outcomeFinished, err
+	}
+	p.SetEncoder(encodeSession)
+	//Session is replaced on seek
+	defer func() {
+		p.SetEncoder(nil)
+		encodeSession.Cleanup()
+	}()
+
+	frameDuration := time.Duration(opts.FrameDuration) * time.Millisecond
+	position := time.Duration(startTime) * time.Second
 
 	vc.Speaking(true)
 	defer vc.Speaking(false)
+	d.transition(gID, p, StatePlaying)
+
+	sendTimer := time.NewTimer(frameTimeout)
+	defer sendTimer.Stop()
+	var frame []byte
 	for {
-		frame, err := encodeSession.OpusFrame()
-		if err != nil {
-			if err != io.EOF {
-				rErr = err
+		//Frames aren't sent while paused, only commands are handled
+		var send chan<- []byte
+		var timeout <-chan time.Time
+		if p.State() == StatePlaying {
+			if frame == nil {
+				frame, err = encodeSession.OpusFrame()
+				if err == io.EOF {
+					return outcomeFinished, nil
+				}
+				if err != nil {
+					return outcomeFinished, err
+				}
 			}
-			return
+			send = vc.Frames()
+			resetTimer(sendTimer, frameTimeout)
+			timeout = sendTimer.C
 		}
 
 		select {
-		case vc.OpusSend <- frame:
-		case <-time.After(5 * time.Second):
-			rErr = fmt.Errorf("connection is broken, unable to send a frame for more than 1 second")
-			return
-
-		case cmd := <-command:
-		pauseLoop:
-			for {
-				switch cmd.Action {
-				case "stop":
-					stop = true
-					return
-				case "skip":
-					return
-				case "resume":
-					//Continue playing song
-					break pauseLoop
-				case "pause":
-					//Stay in the loop, wait for the next command
-					cmd = <-command
-				case "seek":
-					//Re-encode to recover sent frames
-					encodeSession.Cleanup()
-					opts.StartTime = cmd.SeekTime
-					encodeSession, err = dca.EncodeFile(path, opts)
-					if err != nil {
-						rErr = err
-						return
+		case send <- frame:
+			frame = nil
+			position += frameDuration
+			p.SetPosition(position)
+			metrics.FramesSent.Inc()
+		case <-timeout:
+			metrics.VoiceSendTimeouts.Inc()
+			return outcomeFinished, fmt.Errorf("connection is broken, unable to send a frame for %s", frameTimeout)
+
+		case cmd := <-p.Command:
+			switch cmd.action {
+			case actionStop:
+				d.transition(gID, p, StateStopping)
+				cmd.respond(song, nil)
+				return outcomeStopped, nil
+			case actionSkip:
+				cmd.respond(song, nil)
+				return outcomeSkipped, nil
+			case actionPause:
+				if p.State() == StatePaused {
+					//Song paused by the user stays paused when listeners return
+					if !cmd.auto {
+						p.SetAutoPaused(false)
 					}
-					break pauseLoop
+					cmd.respond(song, types.ErrAlreadyPaused)
+					continue
+				}
+				d.transition(gID, p, StatePaused)
+				p.SetAutoPaused(cmd.auto)
+				cmd.respond(song, nil)
+			case actionResume:
+				if p.State() != StatePaused {
+					cmd.respond(song, types.ErrNotPaused)
+					continue
 				}
+				d.transition(gID, p, StatePlaying)
+				cmd.respond(song, nil)
+			case actionSeek:
+				//Re-encode to recover sent frames, paused song stays paused
+				encodeSession.Cleanup()
+				opts.StartTime = int(cmd.position / time.Second)
+				encodeSession, err = d.encode(song.Path, &opts)
+				if err != nil {
+					cmd.respond(song, err)
+					return outcomeFinished, err
+				}
+				p.SetEncoder(encodeSession)
+				frame = nil
+				position = cmd.position
+				p.SetPosition(position)
+				cmd.respond(song, nil)
 			}
 		}
 	}
 }
+
+//resetTimer restarts the timer, draining the channel if it has already fired.
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
@@ -0,0 +1,358 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jonas747/dca"
+	"github.com/relipocere/gotune/internal/discord/types"
+	"github.com/relipocere/gotune/internal/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	testGuild = "guild"
+	testVoice = "voice"
+	testText  = "text"
+	//frameWait is how long frames are waited to be sent or not sent
+	frameWait = 50 * time.Millisecond
+)
+
+//fakeVoice is the voice connection which hands frames to the test.
+type fakeVoice struct {
+	frames chan []byte
+	mux    *sync.Mutex
+	closed bool
+}
+
+func (v *fakeVoice) Speaking(bool) error { return nil }
+
+func (v *fakeVoice) Disconnect() error {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	v.closed = true
+	return nil
+}
+
+func (v *fakeVoice) Frames() chan<- []byte { return v.frames }
+
+func (v *fakeVoice) Connected() bool { return true }
+
+func (v *fakeVoice) disconnected() bool {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	return v.closed
+}
+
+//fakeEncoder produces frames named after the song, endless if length is 0.
+type fakeEncoder struct {
+	path   string
+	length int
+	next   int
+	mux    *sync.Mutex
+	killed bool
+}
+
+func (e *fakeEncoder) OpusFrame() ([]byte, error) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if e.killed || e.length > 0 && e.next >= e.length {
+		return nil, io.EOF
+	}
+	e.next++
+	return []byte(fmt.Sprintf("%s:%d", e.path, e.next)), nil
+}
+
+func (e *fakeEncoder) Stop() error {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.killed = true
+	return nil
+}
+
+func (e *fakeEncoder) Cleanup() {}
+
+//testPlayer is the dispatcher with fake voice connection and encoder.
+type testPlayer struct {
+	*Dispatcher
+	voice *fakeVoice
+	//joining is waited before the voice channel is joined, if it's set
+	joining chan struct{}
+	//lengths are the numbers of frames of the songs by their paths
+	lengths map[string]int
+	states  *stateRecorder
+}
+
+//stateRecorder collects states the player goes through.
+type stateRecorder struct {
+	mux       *sync.Mutex
+	states    []string
+	destroyed chan struct{}
+}
+
+func (r *stateRecorder) handle(e types.Event) {
+	switch e.Type {
+	case types.EventStateChanged:
+		r.mux.Lock()
+		r.states = append(r.states, e.State)
+		r.mux.Unlock()
+	case types.EventPlayerDestroyed:
+		close(r.destroyed)
+	}
+}
+
+func newTestPlayer(t *testing.T) *testPlayer {
+	t.Helper()
+	s, err := discordgo.New("Bot test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tp := &testPlayer{
+		Dispatcher: NewDispatcher(s, zap.NewNop().Sugar(), store, nil, IdleOptions{}),
+		voice:      &fakeVoice{frames: make(chan []byte), mux: &sync.Mutex{}},
+		lengths:    make(map[string]int),
+		states:     &stateRecorder{mux: &sync.Mutex{}, destroyed: make(chan struct{})},
+	}
+	tp.join = func(gID, vID string) (voice, error) {
+		if tp.joining != nil {
+			<-tp.joining
+		}
+		return tp.voice, nil
+	}
+	tp.encode = func(path string, opts *dca.EncodeOptions) (encoder, error) {
+		return &fakeEncoder{path: path, length: tp.lengths[path], next: opts.StartTime, mux: &sync.Mutex{}}, nil
+	}
+	tp.Subscribe(tp.states.handle)
+	t.Cleanup(func() {
+		tp.Stop(testGuild)
+		tp.running.Wait()
+		store.Close()
+	})
+	return tp
+}
+
+//play starts playing the songs with the paths.
+func (tp *testPlayer) play(t *testing.T, paths ...string) {
+	t.Helper()
+	songs := make([]types.Song, len(paths))
+	for n, path := range paths {
+		songs[n] = types.Song{Title: path, Path: path}
+	}
+	if err := tp.Play(testGuild, testVoice, testText, songs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//frame waits for the frame sent to the voice connection.
+func (tp *testPlayer) frame(t *testing.T) string {
+	t.Helper()
+	select {
+	case f := <-tp.voice.frames:
+		return string(f)
+	case <-time.After(time.Second):
+		t.Fatal("frame isn't sent")
+	}
+	return ""
+}
+
+//noFrame checks that frames aren't sent.
+func (tp *testPlayer) noFrame(t *testing.T) {
+	t.Helper()
+	select {
+	case f := <-tp.voice.frames:
+		t.Fatalf("frame %s is sent", f)
+	case <-time.After(frameWait):
+	}
+}
+
+//destroyed waits until the player leaves.
+func (tp *testPlayer) destroyed(t *testing.T) {
+	t.Helper()
+	select {
+	case <-tp.states.destroyed:
+	case <-time.After(time.Second):
+		t.Fatal("player isn't destroyed")
+	}
+	tp.running.Wait()
+}
+
+//waitState waits until the player goes to the state.
+func (tp *testPlayer) waitState(t *testing.T, want State) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for tp.state() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("state = %s, want %s", tp.state(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (tp *testPlayer) state() State {
+	p, ok := tp.players.Load(testGuild)
+	if !ok {
+		return StateStopping
+	}
+	return p.State()
+}
+
+func TestPlayerTransitions(t *testing.T) {
+	tp := newTestPlayer(t)
+	tp.lengths["a"] = 2
+	tp.play(t, "a")
+
+	for _, want := range []string{"a:1", "a:2"} {
+		if got := tp.frame(t); got != want {
+			t.Errorf("frame = %s, want %s", got, want)
+		}
+	}
+	tp.destroyed(t)
+
+	tp.states.mux.Lock()
+	defer tp.states.mux.Unlock()
+	want := []string{"loading", "playing", "loading", "stopping"}
+	if !reflect.DeepEqual(tp.states.states, want) {
+		t.Errorf("states = %v, want %v", tp.states.states, want)
+	}
+	if !tp.voice.disconnected() {
+		t.Error("voice connection isn't closed")
+	}
+}
+
+func TestPlayerPause(t *testing.T) {
+	tp := newTestPlayer(t)
+	tp.play(t, "a")
+	tp.frame(t)
+
+	if err := tp.Pause(testGuild); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if st := tp.state(); st != StatePaused {
+		t.Errorf("state = %s, want paused", st)
+	}
+	tp.noFrame(t)
+	if err := tp.Pause(testGuild); !errors.Is(err, types.ErrAlreadyPaused) {
+		t.Errorf("Pause() while paused error = %v, want %v", err, types.ErrAlreadyPaused)
+	}
+
+	if err := tp.Resume(testGuild); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if got := tp.frame(t); got != "a:2" {
+		t.Errorf("frame after resume = %s, want a:2", got)
+	}
+	if err := tp.Resume(testGuild); !errors.Is(err, types.ErrNotPaused) {
+		t.Errorf("Resume() while playing error = %v, want %v", err, types.ErrNotPaused)
+	}
+}
+
+func TestPlayerStopWhilePaused(t *testing.T) {
+	tp := newTestPlayer(t)
+	tp.play(t, "a", "b")
+	tp.frame(t)
+
+	if err := tp.Pause(testGuild); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if err := tp.Stop(testGuild); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	tp.destroyed(t)
+	tp.noFrame(t)
+	if err := tp.Resume(testGuild); !errors.Is(err, types.ErrNoPlayer) {
+		t.Errorf("Resume() after stop error = %v, want %v", err, types.ErrNoPlayer)
+	}
+}
+
+func TestPlayerSkip(t *testing.T) {
+	tp := newTestPlayer(t)
+	tp.play(t, "a", "b", "c")
+	tp.frame(t)
+
+	skipped, err := tp.Skip(testGuild)
+	if err != nil || skipped.Path != "a" {
+		t.Fatalf("Skip() = %s, %v, want a", skipped.Path, err)
+	}
+	if got := tp.frame(t); got != "b:1" {
+		t.Errorf("frame after skip = %s, want b:1", got)
+	}
+
+	next, err := tp.SkipTo(testGuild, 1)
+	if err != nil || next.Path != "c" {
+		t.Fatalf("SkipTo() = %s, %v, want c", next.Path, err)
+	}
+	if got := tp.frame(t); got != "c:1" {
+		t.Errorf("frame after skip = %s, want c:1", got)
+	}
+	if song, _, ok := tp.NowPlaying(testGuild); !ok || song.Path != "c" {
+		t.Errorf("NowPlaying() = %s, %v, want c", song.Path, ok)
+	}
+}
+
+func TestPlayerSeek(t *testing.T) {
+	tp := newTestPlayer(t)
+	tp.play(t, "a")
+	tp.frame(t)
+
+	if err := tp.Pause(testGuild); err != nil {
+		t.Fatal(err)
+	}
+	if err := tp.Seek(testGuild, 30); err != nil {
+		t.Fatalf("Seek() error = %v", err)
+	}
+	//Paused song stays paused after seeking
+	tp.noFrame(t)
+	if _, position, _ := tp.NowPlaying(testGuild); position != 30*time.Second {
+		t.Errorf("position = %s, want 30s", position)
+	}
+	if err := tp.Resume(testGuild); err != nil {
+		t.Fatal(err)
+	}
+	if got := tp.frame(t); got != "a:31" {
+		t.Errorf("frame after seek = %s, want a:31", got)
+	}
+}
+
+func TestPlayerCommandsWhileLoading(t *testing.T) {
+	tp := newTestPlayer(t)
+	tp.joining = make(chan struct{})
+	tp.play(t, "a")
+
+	tp.waitState(t, StateLoading)
+	for name, cmd := range map[string]func(string) error{"Pause": tp.Pause, "Resume": tp.Resume} {
+		if err := cmd(testGuild); !errors.Is(err, types.ErrNotPlaying) {
+			t.Errorf("%s() while loading error = %v, want %v", name, err, types.ErrNotPlaying)
+		}
+	}
+	if _, err := tp.Skip(testGuild); !errors.Is(err, types.ErrNotPlaying) {
+		t.Errorf("Skip() while loading error = %v, want %v", err, types.ErrNotPlaying)
+	}
+
+	if err := tp.Stop(testGuild); err != nil {
+		t.Fatalf("Stop() while loading error = %v", err)
+	}
+	tp.destroyed(t)
+
+	//Connection joined after the player is stopped is closed
+	close(tp.joining)
+	deadline := time.Now().Add(time.Second)
+	for !tp.voice.disconnected() {
+		if time.Now().After(deadline) {
+			t.Fatal("abandoned voice connection isn't closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	tp.noFrame(t)
+}
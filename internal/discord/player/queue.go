@@ -30,7 +30,9 @@ func (q *queue) Len() int {
 func (q *queue) ListSongs() []types.Song {
 	q.mux.RLock()
 	defer q.mux.RUnlock()
-	return q.songs
+	songs := make([]types.Song, len(q.songs))
+	copy(songs, q.songs)
+	return songs
 }
 
 //Push adds songs to the queue.
@@ -48,3 +50,37 @@ func (q *queue) Pop() types.Song {
 	q.songs = q.songs[1:]
 	return s
 }
+
+//PushFront adds songs to the beginning of the queue.
+func (q *queue) PushFront(s []types.Song) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	q.songs = append(append(make([]types.Song, 0, len(s)+len(q.songs)), s...), q.songs...)
+}
+
+//Remove removes song at the index from the queue and returns it.
+func (q *queue) Remove(i int) (types.Song, bool) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if i < 0 || i >= len(q.songs) {
+		return types.Song{}, false
+	}
+
+	s := q.songs[i]
+	q.songs = append(q.songs[:i:i], q.songs[i+1:]...)
+	return s, true
+}
+
+//Move moves song from one index of the queue to another and returns it.
+func (q *queue) Move(from, to int) (types.Song, bool) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if from < 0 || from >= len(q.songs) || to < 0 || to >= len(q.songs) {
+		return types.Song{}, false
+	}
+
+	s := q.songs[from]
+	songs := append(q.songs[:from:from], q.songs[from+1:]...)
+	q.songs = append(songs[:to:to], append([]types.Song{s}, songs[to:]...)...)
+	return s, true
+}
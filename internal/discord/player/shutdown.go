@@ -0,0 +1,55 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//Players returns snapshots of all guild players.
+func (d *Dispatcher) Players() []types.PlayerState {
+	var states []types.PlayerState
+	d.players.Range(func(gID string, p *player) {
+		states = append(states, p.Snapshot(gID))
+	})
+	return states
+}
+
+//Shutdown stops all guild players and waits until they leave the voice channels,
+//then waits until subscribers handle the events of the players.
+//Players which haven't stopped before the context is done get their ffmpeg processes killed.
+//New players aren't launched once states are persisted, so Persist should be called first.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	var wg sync.WaitGroup
+	d.players.Range(func(gID string, p *player) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.send(p, newCommand(actionStop)).err; err != nil {
+				d.log.Warnw(fmt.Sprintf("stop: %s", err.Error()), "guildID", gID)
+			}
+		}()
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		d.running.Wait()
+		close(stopped)
+	}()
+
+	var err error
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		d.players.Range(func(_ string, p *player) {
+			p.KillEncoder()
+		})
+		err = fmt.Errorf("players haven't stopped: %w", ctx.Err())
+	}
+	//Subscribers save the history, so they finish before the storage is closed
+	return errors.Join(err, d.events.Close(ctx))
+}
@@ -0,0 +1,115 @@
+package player
+
+import (
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//State is the stage of the guild player lifecycle.
+type State int
+
+const (
+	//StateIdle is waiting for songs to be added to the empty queue
+	StateIdle State = iota
+	//StateLoading is joining the voice channel or preparing the next song
+	StateLoading
+	//StatePlaying is sending the song to the voice channel
+	StatePlaying
+	//StatePaused is holding the song until it's resumed
+	StatePaused
+	//StateStopping is leaving the voice channel, commands are no longer accepted
+	StateStopping
+)
+
+var stateNames = [...]string{"idle", "loading", "playing", "paused", "stopping"}
+
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return "unknown"
+	}
+	return stateNames[s]
+}
+
+//action is what the command asks the player to do.
+type action int
+
+const (
+	actionSkip action = iota
+	actionSeek
+	actionPause
+	actionResume
+	actionStop
+)
+
+var actionNames = [...]string{"skip", "seek", "pause", "resume", "stop"}
+
+func (a action) String() string {
+	if a < 0 || int(a) >= len(actionNames) {
+		return "unknown"
+	}
+	return actionNames[a]
+}
+
+//command is the request to the guild player, the result is sent back to the reply channel.
+type command struct {
+	action action
+	//position is the playback time to seek to
+	position time.Duration
+	//auto is set for commands the player sends to itself, e.g. pausing while nobody is listening
+	auto  bool
+	reply chan result
+}
+
+//result is the outcome of the command.
+type result struct {
+	//song is the song the command was applied to
+	song types.Song
+	err  error
+}
+
+func newCommand(a action) command {
+	return command{action: a, reply: make(chan result, 1)}
+}
+
+func newSeekCommand(position time.Duration) command {
+	cmd := newCommand(actionSeek)
+	cmd.position = position
+	return cmd
+}
+
+//respond sends the result back to the dispatcher.
+//Reply channel is buffered, so the player isn't blocked if nobody waits for it anymore.
+func (c command) respond(song types.Song, err error) {
+	c.reply <- result{song: song, err: err}
+}
+
+//State returns the state of the guild player, false is returned if there is no player in the guild.
+func (d *Dispatcher) State(gID string) (State, bool) {
+	p, ok := d.players.Load(gID)
+	if !ok {
+		return StateIdle, false
+	}
+	return p.State(), true
+}
+
+//transition changes the state of the guild player and publishes the change.
+func (d *Dispatcher) transition(gID string, p *player, to State) {
+	from := p.setState(to)
+	if from == to {
+		return
+	}
+	d.log.Debugw("player state changed", "guildID", gID, "from", from.String(), "to", to.String())
+
+	var song *types.Song
+	if cur, _, ok := p.Current(); ok {
+		song = &cur
+	}
+	d.publish(gID, p, types.Event{Type: types.EventStateChanged, Song: song})
+	switch {
+	case to == StatePaused:
+		d.publish(gID, p, types.Event{Type: types.EventPaused, Song: song})
+	case from == StatePaused && to == StatePlaying:
+		d.publish(gID, p, types.Event{Type: types.EventResumed, Song: song})
+	}
+}
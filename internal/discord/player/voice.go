@@ -0,0 +1,57 @@
+package player
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/jonas747/dca"
+)
+
+//voice is the voice connection songs are sent to.
+type voice interface {
+	Speaking(speaking bool) error
+	Disconnect() error
+	//Frames returns the channel opus frames are sent to, it's replaced when the connection is restored
+	Frames() chan<- []byte
+	//Connected reports whether the connection is ready to send frames
+	Connected() bool
+}
+
+//discordVoice is the voice connection of the Discord gateway.
+type discordVoice struct {
+	*discordgo.VoiceConnection
+}
+
+func (v discordVoice) Frames() chan<- []byte {
+	return v.OpusSend
+}
+
+func (v discordVoice) Connected() bool {
+	v.RLock()
+	defer v.RUnlock()
+	return v.Ready
+}
+
+//joinVoice joins the voice channel of the guild.
+func (d *Dispatcher) joinVoice(gID, vID string) (voice, error) {
+	vc, err := d.s.ChannelVoiceJoin(gID, vID, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return discordVoice{vc}, nil
+}
+
+//encoder produces opus frames of the song.
+type encoder interface {
+	OpusFrame() ([]byte, error)
+	//Stop kills ffmpeg process, so the song ends
+	Stop() error
+	Cleanup()
+}
+
+//encodeFile starts ffmpeg session encoding the file.
+func encodeFile(path string, opts *dca.EncodeOptions) (encoder, error) {
+	session, err := dca.EncodeFile(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+//Errors returned by the Dispatcher, the bot tells them apart to choose the message for the member.
+var (
+	//ErrNoPlayer means there is no player in the guild
+	ErrNoPlayer = errors.New("no player in the guild")
+	//ErrNotPlaying means the player is waiting for songs, so there is nothing to control
+	ErrNotPlaying = errors.New("nothing is playing")
+	//ErrAlreadyPaused means pause was requested while the song is paused
+	ErrAlreadyPaused = errors.New("song is already paused")
+	//ErrNotPaused means resume was requested while the song is playing
+	ErrNotPaused = errors.New("song is not paused")
+	//ErrPlayerUnresponsive means the player didn't accept the command in time
+	ErrPlayerUnresponsive = errors.New("player is not responding")
+	//ErrAlreadyInChannel means the player is already in the requested voice channel
+	ErrAlreadyInChannel = errors.New("player is already in the channel")
+	//ErrQueueRange means the position is outside of the queue, see QueueRangeError
+	ErrQueueRange = errors.New("position is out of the queue")
+	//ErrShuttingDown means songs were requested after the queues were saved during shutdown
+	ErrShuttingDown = errors.New("bot is shutting down")
+)
+
+//QueueRangeError is ErrQueueRange with the length of the queue.
+type QueueRangeError struct {
+	Pos int
+	Len int
+}
+
+func (e *QueueRangeError) Error() string {
+	return fmt.Sprintf("position %d is out of the queue of %d songs", e.Pos, e.Len)
+}
+
+//Is makes the error match ErrQueueRange.
+func (e *QueueRangeError) Is(target error) bool {
+	return target == ErrQueueRange
+}
+
+//JoinError is the failure to join the voice channel.
+type JoinError struct {
+	VoiceID string
+	Err     error
+}
+
+func (e *JoinError) Error() string {
+	return fmt.Sprintf("unable to join voice channel %s: %s", e.VoiceID, e.Err.Error())
+}
+
+func (e *JoinError) Unwrap() error {
+	return e.Err
+}
+
+//Reasons of the failed extraction.
+const (
+	//ReasonNotFound means no video matches the query
+	ReasonNotFound = "not_found"
+	//ReasonUnavailable means the video is private, removed or blocked
+	ReasonUnavailable = "unavailable"
+	//ReasonAgeRestricted means the video requires signing in
+	ReasonAgeRestricted = "age_restricted"
+	//ReasonSearchFailed means the search itself failed, e.g. API quota is exceeded
+	ReasonSearchFailed = "search_failed"
+	//ReasonDownloadFailed means the video was found, but it couldn't be downloaded
+	ReasonDownloadFailed = "download_failed"
+)
+
+//ExtractionError is the failure of the Extractor to get songs, Reason is one of the reasons above.
+type ExtractionError struct {
+	Reason string
+	Err    error
+}
+
+func (e *ExtractionError) Error() string {
+	return fmt.Sprintf("extraction failed (%s): %s", e.Reason, e.Err.Error())
+}
+
+func (e *ExtractionError) Unwrap() error {
+	return e.Err
+}
+
+//ExtractionReason returns reason of the failed extraction, empty if the error isn't ExtractionError.
+func ExtractionReason(err error) string {
+	var extErr *ExtractionError
+	if errors.As(err, &extErr) {
+		return extErr.Reason
+	}
+	return ""
+}
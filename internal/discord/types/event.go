@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+//EventType is what happened to the guild player.
+type EventType string
+
+//Events published by the guild players.
+const (
+	EventPlayerCreated   EventType = "player_created"
+	EventPlayerDestroyed EventType = "player_destroyed"
+	EventStateChanged    EventType = "state_changed"
+	EventTrackStarted    EventType = "track_started"
+	EventTrackFinished   EventType = "track_finished"
+	EventTrackSkipped    EventType = "track_skipped"
+	EventTrackErrored    EventType = "track_errored"
+	EventPaused          EventType = "paused"
+	EventResumed         EventType = "resumed"
+	EventQueueChanged    EventType = "queue_changed"
+)
+
+//Event is the change of the guild player.
+type Event struct {
+	Type    EventType
+	GuildID string
+	//VoiceID is the voice channel player is in
+	VoiceID string
+	//TextID is the text channel player was requested from
+	TextID string
+	//Time is when the event happened
+	Time time.Time
+	//State is the state of the player after the event
+	State string
+	//Song is the song the event is about, nil for events not about songs
+	Song *Song
+	//StartedAt is when the song started playing, set for the ended songs
+	StartedAt time.Time
+	//Queue is the queue after the change, set for EventQueueChanged
+	Queue []Song
+	//Err is the reason of the failure, set for EventTrackErrored
+	//and for EventPlayerDestroyed if the player failed to join
+	Err error
+}
@@ -1,16 +1,89 @@
 package types
 
+import (
+	"context"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
 type Extractor interface {
 	Get(query string) ([]Song, error)
+	Download(link string) ([]Song, error)
+	Search(query string, limit int) ([]SearchResult, error)
+	Related(seeds, recent []Song) (Song, error)
+	Close()
+}
+
+type Translator interface {
+	Printer(locale string) Printer
+	Localizations(key string) map[discordgo.Locale]string
+	Locales() []string
+}
+
+type LyricsProvider interface {
+	Find(title string) (Lyrics, error)
 }
 
 type Dispatcher interface {
-	Play(gID, vID, cmdID string, songs []Song)
+	Play(gID, vID, cmdID string, songs []Song) error
+	PlayNext(gID, vID, cmdID string, songs []Song) error
+	Join(gID, vID string) error
+	VoiceChannel(gID string) string
+	TextChannel(gID string) string
 	Queue(gID string) []Song
-	Seek(gID string, seekTime int) (string, error)
-	SkipTo(gID string, pos int) (string, error)
-	Stop(gID string) (string, error)
-	Skip(gID string) (string, error)
-	Pause(gID string) (string, error)
-	Resume(gID string) (string, error)
+	NowPlaying(gID string) (song Song, position time.Duration, ok bool)
+	Seek(gID string, seekTime int) error
+	SkipTo(gID string, pos int) (Song, error)
+	Remove(gID string, pos int) (Song, error)
+	Move(gID string, from, to int) (Song, error)
+	Stop(gID string) error
+	Skip(gID string) (Song, error)
+	Pause(gID string) error
+	Resume(gID string) error
+	Restore(st PlayerState)
+	Persist()
+	Players() []PlayerState
+	Shutdown(ctx context.Context) error
+	Subscribe(h func(Event)) (unsubscribe func())
+}
+
+type GuildDirectory interface {
+	Guilds() []Guild
+	VoiceChannels(gID string) []Channel
+	MemberVoiceChannel(gID, uID string) string
+	CanControl(gID, uID string) bool
+}
+
+type SettingsStore interface {
+	Settings(gID string) (Settings, error)
+	SaveSettings(gID string, s Settings) error
+}
+
+type StateStore interface {
+	State(gID string) (st PlayerState, found bool, err error)
+	SaveState(st PlayerState) error
+	DeleteState(gID string) error
+}
+
+type PlaylistStore interface {
+	Playlist(scope, ownerID, name string) (pl Playlist, found bool, err error)
+	Playlists(scope, ownerID string) ([]Playlist, error)
+	SavePlaylist(pl Playlist) error
+	DeletePlaylist(scope, ownerID, name string) error
+}
+
+type HistoryStore interface {
+	AddHistory(e HistoryEntry) error
+	History(gID string, offset, limit int) (entries []HistoryEntry, total int, err error)
+	HistorySince(gID string, since time.Time) ([]HistoryEntry, error)
+	LastSummary(gID string) (time.Time, error)
+	SetLastSummary(gID string, t time.Time) error
+}
+
+type Store interface {
+	SettingsStore
+	StateStore
+	PlaylistStore
+	HistoryStore
 }
@@ -1,6 +1,12 @@
 package types
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
 
 //Song represents a playable track.
 type Song struct {
@@ -12,4 +18,214 @@ type Song struct {
 	Link string
 	//Requester is the user who requested the song
 	Requester *discordgo.User
+	//Duration of the song, 0 if unknown
+	Duration time.Duration
+	//Chapters of the video, empty if it has none
+	Chapters []Chapter
+}
+
+//SearchResult is the video found by the search.
+type SearchResult struct {
+	Title string
+	Link  string
+}
+
+//Chapter is the named part of the song.
+type Chapter struct {
+	Title string
+	Start time.Duration
+	End   time.Duration
+}
+
+//ChapterAt returns chapter of the song playing at the position.
+func (s Song) ChapterAt(position time.Duration) (Chapter, bool) {
+	for _, ch := range s.Chapters {
+		if position >= ch.Start && position < ch.End {
+			return ch, true
+		}
+	}
+	return Chapter{}, false
+}
+
+//VideoID extracts ID of the video from YouTube link of the song, empty if it has none.
+func (s Song) VideoID() string {
+	u, err := url.Parse(s.Link)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("v")
+}
+
+//Loop modes of the guild player.
+const (
+	LoopOff   = "off"
+	LoopTrack = "track"
+	LoopQueue = "queue"
+)
+
+//Sources songs can be requested from.
+const (
+	SourceSearch   = "search"
+	SourceLink     = "link"
+	SourcePlaylist = "playlist"
+)
+
+//Settings are persistent preferences of the guild.
+type Settings struct {
+	//Volume in percent, 100 is the original loudness
+	Volume int
+	//DJRole is the role required to control playback, empty allows everyone
+	DJRole string
+	//AnnounceChannel is the text channel for now playing messages,
+	//empty means the channel command was issued in
+	AnnounceChannel string
+	//Loop is one of the loop modes
+	Loop string
+	//MaxQueue is the maximum length of the queue, 0 means unlimited
+	MaxQueue int
+	//Sources lists allowed song sources, empty allows all of them
+	Sources []string
+	//SummaryChannel is the text channel for weekly recaps, empty disables them
+	SummaryChannel string
+	//Autoplay keeps playing related songs when the queue is empty
+	Autoplay bool
+	//AlwaysOn is 24/7 mode, player never leaves because of idle timeouts
+	AlwaysOn bool
+	//Prefix starts message commands like !play, empty disables them
+	Prefix string
+	//Locale is the language of the bot messages, empty means the language of the guild
+	Locale string
+}
+
+//DefaultSettings returns settings of the guild that hasn't changed anything.
+func DefaultSettings() Settings {
+	return Settings{
+		Volume: 100,
+		Loop:   LoopOff,
+	}
+}
+
+//Language returns locale of the bot messages, guild locale is used unless it's chosen explicitly.
+func (s Settings) Language(guildLocale string) string {
+	if s.Locale != "" {
+		return s.Locale
+	}
+	return guildLocale
+}
+
+//QuerySource determines from what source the song is requested.
+func QuerySource(query string) string {
+	if !strings.HasPrefix(query, "http") {
+		return SourceSearch
+	}
+	if strings.Contains(query, "list=") {
+		return SourcePlaylist
+	}
+	return SourceLink
+}
+
+//SourceAllowed checks whether songs can be requested from the source.
+func (s Settings) SourceAllowed(source string) bool {
+	if len(s.Sources) == 0 {
+		return true
+	}
+	for _, allowed := range s.Sources {
+		if allowed == source {
+			return true
+		}
+	}
+	return false
+}
+
+//Guild is the server the bot is in.
+type Guild struct {
+	ID   string
+	Name string
+}
+
+//Channel is the channel of the guild.
+type Channel struct {
+	ID   string
+	Name string
+}
+
+//PlayerState is the snapshot of the guild player used to resume playback.
+type PlayerState struct {
+	GuildID string
+	//VoiceID is the voice channel player is in
+	VoiceID string
+	//TextID is the text channel player was requested from
+	TextID string
+	//Current is the song being played
+	Current *Song
+	//Offset is the playback time of the current song in seconds
+	Offset int
+	//Queue are the songs waiting to be played
+	Queue []Song
+}
+
+//Scopes of the saved playlists.
+const (
+	ScopePersonal = "personal"
+	ScopeServer   = "server"
+)
+
+//Playlist is the named list of songs saved by the user.
+type Playlist struct {
+	Name string
+	//Scope is either personal or server
+	Scope string
+	//OwnerID is the user ID for personal playlists and the guild ID for server ones
+	OwnerID string
+	//CreatorID is the user who created the playlist
+	CreatorID string
+	Songs     []Song
+}
+
+//HistoryEntry is the record of the played song.
+type HistoryEntry struct {
+	GuildID   string
+	Song      Song
+	StartedAt time.Time
+	EndedAt   time.Time
+	//Skipped is true if the song was skipped or stopped before the end
+	Skipped bool
+}
+
+//Stats are listening statistics of the guild.
+type Stats struct {
+	//Since is the start of the period, zero means all time
+	Since     time.Time
+	Plays     int
+	Listening time.Duration
+
+	TopSongs      []Count
+	TopRequesters []Count
+	BusiestHours  []Count
+	BusiestDays   []Count
+}
+
+//Count is the number of plays attributed to the name.
+type Count struct {
+	Name  string
+	Count int
 }
+
+//Lyrics of the song.
+type Lyrics struct {
+	Title  string
+	Artist string
+	//Plain is the text of the lyrics
+	Plain string
+	//Synced are timed lines of the lyrics, empty if the provider has none
+	Synced []LyricsLine
+}
+
+//LyricsLine is the line of the synced lyrics.
+type LyricsLine struct {
+	Time time.Duration
+	Text string
+}
+
+//Printer formats the message with the key in the chosen language.
+type Printer func(key string, args ...interface{}) string
@@ -2,6 +2,8 @@ package types
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -10,6 +12,11 @@ const (
 	thumbnailURL     = "https://cdn.discordapp.com/attachments/902158239825788999/902159912967241738/gopher3d.png"
 	colorGo      int = 1500402
 	colorRed     int = 15214375
+
+	//maxDescription is the embed description length limit
+	maxDescription = 4096
+	//maxFieldValue is the embed field value length limit
+	maxFieldValue = 1024
 )
 
 //TextInteractionResp ...
@@ -34,11 +41,27 @@ func EmbedInteractionResp(embed *discordgo.MessageEmbed) *discordgo.InteractionR
 	}
 }
 
+//DeferredInteractionResp acknowledges the interaction, showing that bot is thinking.
+func DeferredInteractionResp() *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}
+}
+
+//Ephemeral makes the response visible only to the member who used the command.
+func Ephemeral(resp *discordgo.InteractionResponse) *discordgo.InteractionResponse {
+	if resp.Data == nil {
+		resp.Data = &discordgo.InteractionResponseData{}
+	}
+	resp.Data.Flags |= discordgo.MessageFlagsEphemeral
+	return resp
+}
+
 //QueueEmbed ...
-func QueueEmbed(songs []Song) *discordgo.MessageEmbed {
+func QueueEmbed(t Printer, songs []Song) *discordgo.MessageEmbed {
 	embed := &discordgo.MessageEmbed{
-		Title:       "Queue",
-		Description: "The queue is empty",
+		Title:       t("queue_title"),
+		Description: t("queue_empty"),
 		Color:       colorGo,
 		Thumbnail: &discordgo.MessageEmbedThumbnail{
 			URL: thumbnailURL,
@@ -46,17 +69,65 @@ func QueueEmbed(songs []Song) *discordgo.MessageEmbed {
 	}
 
 	if songs != nil && len(songs) > 0 {
+		embed.Description = songList(t, songs)
+	}
+	return embed
+}
+
+//PlaylistEmbed ...
+func PlaylistEmbed(t Printer, pl Playlist) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       pl.Name,
+		Description: t("playlist_empty"),
+		Color:       colorGo,
+		Thumbnail: &discordgo.MessageEmbedThumbnail{
+			URL: thumbnailURL,
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: t("playlist_scope", pl.Scope),
+		},
+	}
+
+	if len(pl.Songs) > 0 {
+		embed.Description = songList(t, pl.Songs)
+	}
+	return embed
+}
+
+//PlaylistsEmbed ...
+func PlaylistsEmbed(t Printer, scope string, playlists []Playlist) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       t("playlists_title", scope),
+		Description: t("no_playlists"),
+		Color:       colorGo,
+	}
+
+	if len(playlists) > 0 {
 		var list string
-		for n, song := range songs {
-			list += fmt.Sprintf("%d. %s\n", n+1, song.Title)
+		for _, pl := range playlists {
+			list += t("playlist_entry", pl.Name, len(pl.Songs)) + "\n"
 		}
 		embed.Description = list
 	}
 	return embed
 }
 
+//songList formats numbered list of songs fitting into embed description.
+func songList(t Printer, songs []Song) string {
+	var list string
+	for n, song := range songs {
+		line := fmt.Sprintf("%d. %s\n", n+1, song.Title)
+		if len(list)+len(line) > maxDescription-50 {
+			list += t("and_more", len(songs)-n)
+			break
+		}
+		list += line
+	}
+	return list
+}
+
 //TrackEmbed ...
-func TrackEmbed(message string, s Song) *discordgo.MessageEmbed {
+func TrackEmbed(t Printer, message string, s Song) *discordgo.MessageEmbed {
 	embed := &discordgo.MessageEmbed{
 		URL:   s.Link,
 		Title: s.Title,
@@ -71,18 +142,251 @@ func TrackEmbed(message string, s Song) *discordgo.MessageEmbed {
 
 	if s.Requester != nil {
 		embed.Footer = &discordgo.MessageEmbedFooter{
-			Text:    fmt.Sprintf("Requested by %s", s.Requester.Username),
+			Text:    t("requested_by", s.Requester.Username),
 			IconURL: s.Requester.AvatarURL("32x32"),
 		}
 	}
 	return embed
 }
 
+//NowPlayingEmbed ...
+func NowPlayingEmbed(t Printer, s Song, position time.Duration) *discordgo.MessageEmbed {
+	embed := TrackEmbed(t, t("now_playing"), s)
+
+	progress := FormatDuration(position)
+	if s.Duration > 0 {
+		progress += " / " + FormatDuration(s.Duration)
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: t("position"), Value: progress, Inline: true})
+
+	if ch, ok := s.ChapterAt(position); ok {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: t("chapter"), Value: ch.Title, Inline: true})
+	}
+	return embed
+}
+
+//FormatDuration formats duration as h:mm:ss or m:ss.
+func FormatDuration(d time.Duration) string {
+	sec := int(d / time.Second)
+	if sec >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", sec/3600, sec%3600/60, sec%60)
+	}
+	return fmt.Sprintf("%d:%02d", sec/60, sec%60)
+}
+
 //ErrorEmbed ...
-func ErrorEmbed(message string) *discordgo.MessageEmbed {
+func ErrorEmbed(t Printer, message string) *discordgo.MessageEmbed {
 	return &discordgo.MessageEmbed{
-		Title:       "Error",
+		Title:       t("error_title"),
 		Description: message,
 		Color:       colorRed,
 	}
 }
+
+//SettingsEmbed ...
+func SettingsEmbed(t Printer, s Settings) *discordgo.MessageEmbed {
+	djRole := t("everyone")
+	if s.DJRole != "" {
+		djRole = fmt.Sprintf("<@&%s>", s.DJRole)
+	}
+
+	announce := t("command_channel")
+	if s.AnnounceChannel != "" {
+		announce = fmt.Sprintf("<#%s>", s.AnnounceChannel)
+	}
+
+	maxQueue := t("unlimited")
+	if s.MaxQueue > 0 {
+		maxQueue = fmt.Sprintf("%d", s.MaxQueue)
+	}
+
+	sources := t("all")
+	if len(s.Sources) > 0 {
+		sources = strings.Join(s.Sources, ", ")
+	}
+
+	summary := t("disabled")
+	if s.SummaryChannel != "" {
+		summary = fmt.Sprintf("<#%s>", s.SummaryChannel)
+	}
+
+	prefix := t("disabled")
+	if s.Prefix != "" {
+		prefix = fmt.Sprintf("`%s`", s.Prefix)
+	}
+
+	language := t("server_language")
+	if s.Locale != "" {
+		language = s.Locale
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: t("settings_title"),
+		Color: colorGo,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: t("settings_volume"), Value: fmt.Sprintf("%d%%", s.Volume), Inline: true},
+			{Name: t("settings_loop"), Value: s.Loop, Inline: true},
+			{Name: t("settings_autoplay"), Value: onOff(t, s.Autoplay), Inline: true},
+			{Name: t("settings_always_on"), Value: onOff(t, s.AlwaysOn), Inline: true},
+			{Name: t("settings_max_queue"), Value: maxQueue, Inline: true},
+			{Name: t("settings_dj_role"), Value: djRole, Inline: true},
+			{Name: t("settings_announcements"), Value: announce, Inline: true},
+			{Name: t("settings_sources"), Value: sources, Inline: true},
+			{Name: t("settings_summary"), Value: summary, Inline: true},
+			{Name: t("settings_prefix"), Value: prefix, Inline: true},
+			{Name: t("settings_language"), Value: language, Inline: true},
+		},
+	}
+}
+
+//HistoryEmbed ...
+func HistoryEmbed(t Printer, entries []HistoryEntry, page, pages int) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       t("history_title"),
+		Description: t("nothing_played"),
+		Color:       colorGo,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: t("page", page, pages),
+		},
+	}
+
+	if len(entries) > 0 {
+		var list string
+		for _, e := range entries {
+			line := fmt.Sprintf("[%s](%s) <t:%d:R>", e.Song.Title, e.Song.Link, e.StartedAt.Unix())
+			if e.Song.Requester != nil {
+				line += t("history_requester", e.Song.Requester.Username)
+			}
+			if e.Skipped {
+				line += t("history_skipped", e.EndedAt.Unix())
+			}
+			list += line + "\n"
+		}
+		embed.Description = list
+	}
+	return embed
+}
+
+//StatsEmbed ...
+func StatsEmbed(t Printer, title string, st Stats) *discordgo.MessageEmbed {
+	period := t("stats_all_time")
+	if !st.Since.IsZero() {
+		period = t("stats_since", st.Since.Unix())
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       title,
+		Description: period,
+		Color:       colorGo,
+		Thumbnail: &discordgo.MessageEmbedThumbnail{
+			URL: thumbnailURL,
+		},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: t("stats_plays"), Value: fmt.Sprintf("%d", st.Plays), Inline: true},
+			{Name: t("stats_hours"), Value: fmt.Sprintf("%.1f", st.Listening.Hours()), Inline: true},
+			{Name: t("stats_top_songs"), Value: countList(st.TopSongs)},
+			{Name: t("stats_top_requesters"), Value: countList(st.TopRequesters)},
+			{Name: t("stats_busiest_hours"), Value: countList(st.BusiestHours), Inline: true},
+			{Name: t("stats_busiest_days"), Value: countList(st.BusiestDays), Inline: true},
+		},
+	}
+}
+
+//countList formats numbered list of counts fitting into embed field.
+func countList(counts []Count) string {
+	if len(counts) < 1 {
+		return "—"
+	}
+
+	var list string
+	for n, c := range counts {
+		line := fmt.Sprintf("%d. %s (%d)\n", n+1, c.Name, c.Count)
+		if len(list)+len(line) > maxFieldValue {
+			break
+		}
+		list += line
+	}
+	return list
+}
+
+func onOff(t Printer, b bool) string {
+	if b {
+		return t("on")
+	}
+	return t("off")
+}
+
+//LyricsEmbed ...
+func LyricsEmbed(t Printer, l Lyrics, text string, page, pages int) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       l.Title,
+		Description: text,
+		Color:       colorGo,
+	}
+	if l.Artist != "" {
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: l.Artist}
+	}
+	if pages > 1 {
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: t("page", page, pages),
+		}
+	}
+	return embed
+}
+
+//SyncedLyricsEmbed ...
+//Lines around the playback position are shown, current line is in bold.
+func SyncedLyricsEmbed(t Printer, l Lyrics, position time.Duration) *discordgo.MessageEmbed {
+	const before, after = 4, 8
+
+	cur := -1
+	for n, line := range l.Synced {
+		if line.Time > position {
+			break
+		}
+		cur = n
+	}
+
+	from, to := cur-before, cur+after+1
+	if from < 0 {
+		from = 0
+	}
+	if to > len(l.Synced) {
+		to = len(l.Synced)
+	}
+
+	var text string
+	for n := from; n < to; n++ {
+		line := l.Synced[n].Text
+		if line == "" {
+			line = "♪"
+		}
+		if n == cur {
+			line = fmt.Sprintf("**%s**", line)
+		}
+		text += line + "\n"
+	}
+
+	embed := LyricsEmbed(t, l, text, 1, 1)
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: FormatDuration(position),
+	}
+	return embed
+}
+
+//SplitPages splits text by lines into pages fitting into embed description.
+func SplitPages(text string) []string {
+	var pages []string
+	var page string
+	for _, line := range strings.Split(text, "\n") {
+		if len(page)+len(line)+1 > maxDescription {
+			pages = append(pages, page)
+			page = ""
+		}
+		page += line + "\n"
+	}
+	if strings.TrimSpace(page) != "" || len(pages) == 0 {
+		pages = append(pages, page)
+	}
+	return pages
+}
@@ -0,0 +1,193 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//gatewayTimeout is how long the gateway may go without heartbeat acknowledgement before the bot is considered wedged
+	gatewayTimeout = 5 * time.Minute
+	//toolTimeout is how long the binary may take to print its version
+	toolTimeout = 10 * time.Second
+	//toolCheckInterval is how long the result of the binary check is reused,
+	//so frequent probes don't spawn processes every time
+	toolCheckInterval = time.Minute
+)
+
+//GatewayState reports whether the gateway is connected and when the last heartbeat was acknowledged.
+type GatewayState func() (connected bool, lastAck time.Time)
+
+//Checker reports liveness and readiness of the bot.
+type Checker struct {
+	gateway GatewayState
+	//folder is the audio folder songs are downloaded to
+	folder string
+	//lastExtraction returns time of the last successful extraction, zero if there was none
+	lastExtraction func() time.Time
+	started        time.Time
+	tools          map[string]*toolCheck
+}
+
+//Check is the result of a single check.
+type Check struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+//Report is the response of the health endpoints.
+type Report struct {
+	Status string           `json:"status"`
+	Checks map[string]Check `json:"checks"`
+}
+
+//New creates the checker of the bot.
+func New(gateway GatewayState, folder string, lastExtraction func() time.Time) *Checker {
+	return &Checker{
+		gateway:        gateway,
+		folder:         folder,
+		lastExtraction: lastExtraction,
+		started:        time.Now(),
+		tools: map[string]*toolCheck{
+			"yt-dlp": newToolCheck("yt-dlp", "--version"),
+			"ffmpeg": newToolCheck("ffmpeg", "-version"),
+		},
+	}
+}
+
+//Liveness fails when the gateway has been silent for too long, so the bot should be restarted.
+//Other checks are reported, but they don't affect the status.
+func (c *Checker) Liveness(w http.ResponseWriter, _ *http.Request) {
+	checks := c.checks()
+	respond(w, checks, !c.wedged())
+}
+
+//Readiness fails unless the gateway is connected, the binaries are runnable and the audio folder is writable.
+//Last extraction is reported, but it doesn't affect the status.
+func (c *Checker) Readiness(w http.ResponseWriter, _ *http.Request) {
+	checks := c.checks()
+	ready := true
+	for name, check := range checks {
+		if name != "lastExtraction" && !check.OK {
+			ready = false
+		}
+	}
+	respond(w, checks, ready)
+}
+
+//checks runs all the checks.
+func (c *Checker) checks() map[string]Check {
+	checks := map[string]Check{
+		"gateway":        c.checkGateway(),
+		"audioDir":       c.checkFolder(),
+		"lastExtraction": c.checkExtraction(),
+	}
+	for name, tool := range c.tools {
+		checks[name] = tool.Check()
+	}
+	return checks
+}
+
+func (c *Checker) checkGateway() Check {
+	connected, lastAck := c.gateway()
+	if !connected {
+		return Check{Detail: "disconnected"}
+	}
+	if c.wedged() {
+		return Check{Detail: fmt.Sprintf("no heartbeat acknowledgement since %s", lastAck.Format(time.RFC3339))}
+	}
+	return Check{OK: true, Detail: fmt.Sprintf("connected, last heartbeat acknowledged at %s", lastAck.Format(time.RFC3339))}
+}
+
+//wedged checks whether the gateway hasn't acknowledged heartbeats for too long.
+//Bot which has never connected is given the same time to connect after the start.
+func (c *Checker) wedged() bool {
+	_, lastAck := c.gateway()
+	if lastAck.IsZero() {
+		lastAck = c.started
+	}
+	return time.Since(lastAck) > gatewayTimeout
+}
+
+func (c *Checker) checkFolder() Check {
+	f, err := os.CreateTemp(c.folder, ".healthz-*")
+	if err != nil {
+		return Check{Detail: err.Error()}
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return Check{OK: true, Detail: c.folder}
+}
+
+func (c *Checker) checkExtraction() Check {
+	last := c.lastExtraction()
+	if last.IsZero() {
+		return Check{Detail: "no successful extraction yet"}
+	}
+	return Check{OK: true, Detail: last.Format(time.RFC3339)}
+}
+
+//respond writes the report, failed status is responded with 503.
+func respond(w http.ResponseWriter, checks map[string]Check, ok bool) {
+	report := Report{Status: "ok", Checks: checks}
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		report.Status = "fail"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+//toolCheck checks whether the binary is present and runnable, reusing the result for a while.
+type toolCheck struct {
+	name string
+	args []string
+
+	mux     *sync.Mutex
+	result  Check
+	checked time.Time
+}
+
+func newToolCheck(name string, args ...string) *toolCheck {
+	return &toolCheck{
+		name: name,
+		args: args,
+		mux:  &sync.Mutex{},
+	}
+}
+
+//Check runs the binary, unless it was checked recently.
+func (t *toolCheck) Check() Check {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if !t.checked.IsZero() && time.Since(t.checked) < toolCheckInterval {
+		return t.result
+	}
+
+	t.result = t.run()
+	t.checked = time.Now()
+	return t.result
+}
+
+func (t *toolCheck) run() Check {
+	path, err := exec.LookPath(t.name)
+	if err != nil {
+		return Check{Detail: err.Error()}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), toolTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, t.args...).Output()
+	if err != nil {
+		return Check{Detail: fmt.Sprintf("%s: %s", path, err.Error())}
+	}
+	version, _, _ := strings.Cut(string(out), "\n")
+	return Check{OK: true, Detail: strings.TrimSpace(version)}
+}
@@ -0,0 +1,147 @@
+package locale
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//Default is the locale used when there is no translation for the requested one.
+const Default = "en-US"
+
+//go:embed locales/*.json
+var builtin embed.FS
+
+//Catalog holds messages of the bot translated to the locales.
+type Catalog struct {
+	//messages are indexed by locale then by key
+	messages map[string]map[string]string
+}
+
+//New loads built-in translations and translations from the folder.
+//Files are named after Discord locales, e.g. de.json or pt-BR.json, and map message keys to the text.
+//Messages from the folder override built-in ones, missing folder is ignored.
+func New(folder string) (*Catalog, error) {
+	c := &Catalog{messages: make(map[string]map[string]string)}
+
+	files, err := builtin.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		data, err := builtin.ReadFile("locales/" + f.Name())
+		if err != nil {
+			return nil, err
+		}
+		err = c.load(f.Name(), data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if folder == "" {
+		return c, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(folder, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		err = c.load(filepath.Base(path), data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+//load adds messages from the translation file.
+func (c *Catalog) load(name string, data []byte) error {
+	var messages map[string]string
+	err := json.Unmarshal(data, &messages)
+	if err != nil {
+		return fmt.Errorf("invalid translation file %s: %w", name, err)
+	}
+
+	loc := strings.TrimSuffix(name, filepath.Ext(name))
+	if c.messages[loc] == nil {
+		c.messages[loc] = make(map[string]string, len(messages))
+	}
+	for key, text := range messages {
+		c.messages[loc][key] = text
+	}
+	return nil
+}
+
+//Printer returns function formatting the messages in the locale.
+func (c *Catalog) Printer(loc string) types.Printer {
+	return func(key string, args ...interface{}) string {
+		text, ok := c.lookup(loc, key)
+		if !ok {
+			text, ok = c.lookup(Default, key)
+		}
+		if !ok {
+			text = key
+		}
+
+		if len(args) == 0 {
+			return text
+		}
+		return fmt.Sprintf(text, args...)
+	}
+}
+
+//lookup finds the message in the locale or in its language, e.g. pt for pt-BR.
+func (c *Catalog) lookup(loc, key string) (string, bool) {
+	if text, ok := c.messages[loc][key]; ok {
+		return text, true
+	}
+	if lang, _, found := strings.Cut(loc, "-"); found {
+		text, ok := c.messages[lang][key]
+		return text, ok
+	}
+	return "", false
+}
+
+//Localizations returns translations of the message to all locales except the default one.
+//Nil is returned if there are none.
+func (c *Catalog) Localizations(key string) map[discordgo.Locale]string {
+	var localizations map[discordgo.Locale]string
+	for loc, messages := range c.messages {
+		text, ok := messages[key]
+		if !ok || loc == Default {
+			continue
+		}
+		//Discord accepts only the locales it supports
+		if _, supported := discordgo.Locales[discordgo.Locale(loc)]; !supported {
+			continue
+		}
+
+		if localizations == nil {
+			localizations = make(map[discordgo.Locale]string)
+		}
+		localizations[discordgo.Locale(loc)] = text
+	}
+	return localizations
+}
+
+//Locales lists locales having translations.
+func (c *Catalog) Locales() []string {
+	locales := make([]string, 0, len(c.messages))
+	for loc := range c.messages {
+		locales = append(locales, loc)
+	}
+	sort.Strings(locales)
+	return locales
+}
@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"strings"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -34,15 +36,15 @@ func NewLogger(options ...func(*zap.Config)) (*zap.SugaredLogger, error) {
 	return logger.Sugar(), nil
 }
 
-//SetLevel returns function that sets logger level.
+//SetLevel returns function that sets logger level, unknown levels fall back to ERROR.
 func SetLevel(level string) func(*zap.Config) {
 	return func(c *zap.Config) {
-		switch level {
+		switch strings.ToUpper(level) {
 		case "DEBUG":
 			c.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
 		case "INFO":
 			c.Level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
-		case "WARN":
+		case "WARN", "WARNING":
 			c.Level = zap.NewAtomicLevelAt(zapcore.WarnLevel)
 		case "FATAL":
 			c.Level = zap.NewAtomicLevelAt(zapcore.FatalLevel)
@@ -0,0 +1,69 @@
+package lyrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//File is the lyrics provider reading lyrics from the folder, so it works offline.
+//Files are named after the song, e.g. "Artist - Song.lrc" for synced lyrics
+//or "Artist - Song.txt" for plain ones.
+type File struct {
+	folder string
+}
+
+//NewFile creates provider reading lyrics from the folder.
+func NewFile(folder string) File {
+	return File{folder: folder}
+}
+
+//Find looks for the file whose name matches the title of the song.
+//Exact match is preferred, otherwise file name must be a part of the title.
+func (f File) Find(title string) (types.Lyrics, error) {
+	entries, err := os.ReadDir(f.folder)
+	if err != nil {
+		return types.Lyrics{}, err
+	}
+
+	query := normalize(CleanTitle(title))
+	var match string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".lrc" && ext != ".txt") {
+			continue
+		}
+
+		name := normalize(strings.TrimSuffix(entry.Name(), ext))
+		if name == query {
+			match = entry.Name()
+			break
+		}
+		if name != "" && strings.Contains(query, name) && match == "" {
+			match = entry.Name()
+		}
+	}
+	if match == "" {
+		return types.Lyrics{}, ErrNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(f.folder, match))
+	if err != nil {
+		return types.Lyrics{}, err
+	}
+
+	lyr := types.Lyrics{Title: strings.TrimSuffix(match, filepath.Ext(match))}
+	if filepath.Ext(match) == ".lrc" {
+		lyr.Synced = ParseLRC(string(data))
+		lyr.Plain = PlainFromSynced(lyr.Synced)
+	} else {
+		lyr.Plain = strings.TrimSpace(string(data))
+	}
+	return lyr, nil
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
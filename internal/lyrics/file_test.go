@@ -0,0 +1,146 @@
+package lyrics
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+const songLRC = `[ar:Artist]
+[ti:Song]
+[00:01.00]first line
+[00:05.50]second line
+[00:09.00][00:20.00]chorus
+[00:12.25]
+[00:15.00]third line
+`
+
+//newFolder writes lyrics files to a temporary folder.
+func newFolder(t *testing.T, files map[string]string) File {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewFile(dir)
+}
+
+//keyPrinter prints the key and the arguments instead of the translation.
+func keyPrinter(key string, args ...interface{}) string {
+	return fmt.Sprint(append([]interface{}{key}, args...)...)
+}
+
+func TestFileFind(t *testing.T) {
+	f := newFolder(t, map[string]string{
+		"Artist - Song.lrc":       songLRC,
+		"Artist - Song Remix.txt": "remix",
+		"Other.txt":               "other",
+		"notes.md":                "not lyrics",
+	})
+
+	tests := []struct {
+		title     string
+		wantTitle string
+		wantErr   error
+	}{
+		{title: "Artist - Song (Official Video)", wantTitle: "Artist - Song"},
+		{title: "artist  -  song remix [Lyrics]", wantTitle: "Artist - Song Remix"},
+		{title: "Best of Other Artists", wantTitle: "Other"},
+		{title: "notes", wantErr: ErrNotFound},
+		{title: "Unknown", wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			lyr, err := f.Find(tt.title)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Find() error = %v, want %v", err, tt.wantErr)
+			}
+			if lyr.Title != tt.wantTitle {
+				t.Errorf("Find() title = %q, want %q", lyr.Title, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestFileSynced(t *testing.T) {
+	f := newFolder(t, map[string]string{"Artist - Song.lrc": songLRC})
+	lyr, err := f.Find("Artist - Song")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lyr.Synced) != 6 {
+		t.Fatalf("got %d synced lines, want 6", len(lyr.Synced))
+	}
+	if !strings.HasPrefix(lyr.Plain, "first line\nsecond line\nchorus") {
+		t.Errorf("plain lyrics = %q", lyr.Plain)
+	}
+
+	tests := []struct {
+		position time.Duration
+		current  string
+	}{
+		{position: 0},
+		{position: time.Second, current: "**first line**"},
+		{position: 7 * time.Second, current: "**second line**"},
+		{position: 12250 * time.Millisecond, current: "**♪**"},
+		{position: 25 * time.Second, current: "**chorus**"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.position.String(), func(t *testing.T) {
+			text := types.SyncedLyricsEmbed(keyPrinter, lyr, tt.position).Description
+			bold := strings.Count(text, "**") / 2
+			if tt.current == "" {
+				if bold != 0 {
+					t.Errorf("no line is current yet, got %q", text)
+				}
+				return
+			}
+			if bold != 1 || !strings.Contains(text, tt.current) {
+				t.Errorf("current line must be %s, got %q", tt.current, text)
+			}
+		})
+	}
+}
+
+func TestFilePages(t *testing.T) {
+	var lines []string
+	for n := 0; n < 300; n++ {
+		lines = append(lines, fmt.Sprintf("line number %03d of the long song", n))
+	}
+	f := newFolder(t, map[string]string{"Long Song.txt": strings.Join(lines, "\n") + "\n\n"})
+	lyr, err := f.Find("Long Song")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages := types.SplitPages(lyr.Plain)
+	if len(pages) < 2 {
+		t.Fatalf("got %d pages, want the lyrics split", len(pages))
+	}
+	var joined string
+	for n, page := range pages {
+		if len(page) > 4096 {
+			t.Errorf("page %d is %d bytes long, more than fits into embed", n+1, len(page))
+		}
+		joined += page
+	}
+	if strings.TrimSpace(joined) != strings.Join(lines, "\n") {
+		t.Error("pages don't add up to the lyrics")
+	}
+
+	embed := types.LyricsEmbed(keyPrinter, lyr, pages[1], 2, len(pages))
+	if embed.Footer == nil || embed.Footer.Text != fmt.Sprint("page", 2, len(pages)) {
+		t.Errorf("footer of the second page = %+v", embed.Footer)
+	}
+	if single := types.SplitPages("short"); len(single) != 1 {
+		t.Errorf("short lyrics got %d pages, want 1", len(single))
+	}
+}
@@ -0,0 +1,70 @@
+package lyrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+const lrclibURL = "https://lrclib.net/api/search"
+
+//LRCLib is the lyrics provider backed by lrclib.net, which has synced lyrics.
+type LRCLib struct {
+	client *http.Client
+}
+
+//NewLRCLib creates lrclib.net client.
+func NewLRCLib() LRCLib {
+	return LRCLib{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type lrclibTrack struct {
+	TrackName    string `json:"trackName"`
+	ArtistName   string `json:"artistName"`
+	PlainLyrics  string `json:"plainLyrics"`
+	SyncedLyrics string `json:"syncedLyrics"`
+}
+
+//Find searches lyrics of the song by its title.
+func (l LRCLib) Find(title string) (types.Lyrics, error) {
+	query := url.Values{"q": {CleanTitle(title)}}
+	resp, err := l.client.Get(lrclibURL + "?" + query.Encode())
+	if err != nil {
+		return types.Lyrics{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return types.Lyrics{}, fmt.Errorf("lrclib responded with %s", resp.Status)
+	}
+
+	var tracks []lrclibTrack
+	err = json.NewDecoder(resp.Body).Decode(&tracks)
+	if err != nil {
+		return types.Lyrics{}, err
+	}
+
+	for _, track := range tracks {
+		if track.PlainLyrics == "" && track.SyncedLyrics == "" {
+			continue
+		}
+
+		lyr := types.Lyrics{
+			Title:  track.TrackName,
+			Artist: track.ArtistName,
+			Plain:  track.PlainLyrics,
+			Synced: ParseLRC(track.SyncedLyrics),
+		}
+		if lyr.Plain == "" {
+			lyr.Plain = PlainFromSynced(lyr.Synced)
+		}
+		return lyr, nil
+	}
+	return types.Lyrics{}, ErrNotFound
+}
@@ -0,0 +1,67 @@
+package lyrics
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//ErrNotFound is returned when provider has no lyrics for the song.
+var ErrNotFound = errors.New("lyrics not found")
+
+var (
+	//noise matches parts of YouTube titles that aren't the song name,
+	//e.g. (Official Video), [Lyrics] or "Official Music Video"
+	noise = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]|(?i)\bofficial( music| lyric)? (video|audio)\b`)
+	//lrcTime matches LRC timestamps, e.g. [01:23.45]
+	lrcTime = regexp.MustCompile(`\[(\d+):(\d+(?:\.\d+)?)\]`)
+)
+
+//CleanTitle strips YouTube title of the noise, so it can be used as the search query.
+func CleanTitle(title string) string {
+	title = noise.ReplaceAllString(title, " ")
+	title = strings.ReplaceAll(title, "|", " ")
+	return strings.Join(strings.Fields(title), " ")
+}
+
+//ParseLRC parses synced lyrics in LRC format.
+//Lines without timestamps, like metadata tags, are skipped.
+func ParseLRC(lrc string) []types.LyricsLine {
+	var lines []types.LyricsLine
+	for _, raw := range strings.Split(lrc, "\n") {
+		stamps := lrcTime.FindAllStringSubmatch(raw, -1)
+		if len(stamps) < 1 {
+			continue
+		}
+
+		text := strings.TrimSpace(lrcTime.ReplaceAllString(raw, ""))
+		//The same line can be repeated at several timestamps
+		for _, stamp := range stamps {
+			min, _ := strconv.Atoi(stamp[1])
+			sec, _ := strconv.ParseFloat(stamp[2], 64)
+			lines = append(lines, types.LyricsLine{
+				Time: time.Duration(min)*time.Minute + time.Duration(sec*float64(time.Second)),
+				Text: text,
+			})
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Time < lines[j].Time
+	})
+	return lines
+}
+
+//PlainFromSynced returns text of the synced lyrics.
+func PlainFromSynced(lines []types.LyricsLine) string {
+	texts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		texts = append(texts, line.Text)
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//Extractor measures latency and failures of the wrapped extractor.
+type Extractor struct {
+	types.Extractor
+}
+
+//InstrumentExtractor wraps the extractor to measure it.
+func InstrumentExtractor(e types.Extractor) Extractor {
+	return Extractor{Extractor: e}
+}
+
+//Get downloads the songs matching the query.
+func (e Extractor) Get(query string) ([]types.Song, error) {
+	start := time.Now()
+	songs, err := e.Extractor.Get(query)
+	observeExtraction("get", start, err)
+	return songs, err
+}
+
+//Download downloads the songs of the link.
+func (e Extractor) Download(link string) ([]types.Song, error) {
+	start := time.Now()
+	songs, err := e.Extractor.Download(link)
+	observeExtraction("download", start, err)
+	return songs, err
+}
+
+//Search returns videos matching the query.
+func (e Extractor) Search(query string, limit int) ([]types.SearchResult, error) {
+	start := time.Now()
+	results, err := e.Extractor.Search(query, limit)
+	observeExtraction("search", start, err)
+	return results, err
+}
+
+//Related downloads a song related to the seeds.
+func (e Extractor) Related(seeds, recent []types.Song) (types.Song, error) {
+	start := time.Now()
+	song, err := e.Extractor.Related(seeds, recent)
+	observeExtraction("related", start, err)
+	return song, err
+}
+
+//lastExtraction is the time of the last successful extraction in nanoseconds
+var lastExtraction atomic.Int64
+
+//LastExtractionTime returns time of the last successful extraction, zero if there was none.
+func LastExtractionTime() time.Time {
+	ns := lastExtraction.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+func observeExtraction(operation string, start time.Time, err error) {
+	ExtractionDuration.WithLabelValues(operation).Observe(Since(start))
+	if err == nil {
+		now := time.Now()
+		lastExtraction.Store(now.UnixNano())
+		LastExtraction.Set(float64(now.Unix()))
+		return
+	}
+
+	reason := types.ExtractionReason(err)
+	if reason == "" {
+		reason = "unknown"
+	}
+	ExtractionFailures.WithLabelValues(operation, reason).Inc()
+}
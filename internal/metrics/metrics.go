@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gotune"
+
+//registry holds metrics of the bot, they are collected whether or not the listener is enabled.
+var registry = prometheus.NewRegistry()
+
+var factory = promauto.With(registry)
+
+var (
+	//Players is the number of active guild players
+	Players = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "players_active",
+		Help:      "Number of active guild players.",
+	})
+	//QueueLength is the number of queued songs per guild
+	QueueLength = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_length",
+		Help:      "Number of songs in the queue of the guild player.",
+	}, []string{"guild"})
+	//Tracks counts ended songs by how they ended
+	Tracks = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tracks_total",
+		Help:      "Number of songs which stopped playing by how they ended.",
+	}, []string{"event"})
+
+	//ExtractionDuration is the latency of the extractor operations
+	ExtractionDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "extraction_duration_seconds",
+		Help:      "Latency of the song extraction by operation.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"operation"})
+	//ExtractionFailures counts failed extractions by reason
+	ExtractionFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "extraction_failures_total",
+		Help:      "Number of failed extractions by operation and reason.",
+	}, []string{"operation", "reason"})
+	//LastExtraction is the time of the last successful extraction
+	LastExtraction = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_extraction_timestamp_seconds",
+		Help:      "Unix time of the last successful extraction.",
+	})
+	//YtdlpProcesses is the number of running yt-dlp processes
+	YtdlpProcesses = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ytdlp_processes",
+		Help:      "Number of running yt-dlp processes.",
+	})
+	//SearchCache counts search cache lookups by result, either hit or miss
+	SearchCache = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "search_cache_requests_total",
+		Help:      "Number of search cache lookups by result.",
+	}, []string{"result"})
+
+	//FramesSent counts opus frames sent to the voice connections
+	FramesSent = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "frames_sent_total",
+		Help:      "Number of audio frames sent to the voice connections.",
+	})
+	//VoiceSendTimeouts counts songs ended because the voice connection didn't accept a frame in time
+	VoiceSendTimeouts = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "voice_send_timeouts_total",
+		Help:      "Number of songs ended because the voice connection didn't accept an audio frame in time.",
+	})
+	//VoiceReconnects counts voice connections which were restored after they were lost
+	VoiceReconnects = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "voice_reconnects_total",
+		Help:      "Number of voice connections restored after they were lost.",
+	})
+
+	//InteractionDuration is the latency of the command handlers
+	InteractionDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "interaction_duration_seconds",
+		Help:      "Latency of handling the interactions by command.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"command"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+//WatchCacheDirectory exposes size of the audio folder the songs are downloaded to.
+//Folder is measured when metrics are scraped.
+func WatchCacheDirectory(folder string) {
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_disk_usage_bytes",
+		Help:      "Size of the downloaded songs.",
+	}, func() float64 {
+		return float64(directorySize(folder))
+	})
+}
+
+//directorySize sums sizes of the files in the folder, unreadable files are skipped.
+func directorySize(folder string) int64 {
+	var size int64
+	filepath.WalkDir(folder, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+//Handler returns handler serving the metrics in Prometheus format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+//Since returns seconds passed since the start.
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+//Observe updates metrics of the guild players from their events.
+func Observe(e types.Event) {
+	switch e.Type {
+	case types.EventPlayerCreated:
+		Players.Inc()
+	case types.EventPlayerDestroyed:
+		Players.Dec()
+		QueueLength.DeleteLabelValues(e.GuildID)
+	case types.EventQueueChanged:
+		QueueLength.WithLabelValues(e.GuildID).Set(float64(len(e.Queue)))
+	case types.EventTrackFinished, types.EventTrackSkipped, types.EventTrackErrored:
+		Tracks.WithLabelValues(string(e.Type)).Inc()
+	}
+}
@@ -0,0 +1,64 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//topN is the length of the leaderboards.
+const topN = 5
+
+//Compute aggregates history entries into listening statistics.
+//Busiest times are measured in UTC.
+func Compute(entries []types.HistoryEntry, since time.Time) types.Stats {
+	st := types.Stats{
+		Since: since,
+		Plays: len(entries),
+	}
+
+	songs := make(map[string]int)
+	requesters := make(map[string]int)
+	hours := make(map[string]int)
+	days := make(map[string]int)
+	for _, e := range entries {
+		st.Listening += e.EndedAt.Sub(e.StartedAt)
+
+		songs[e.Song.Title]++
+		if e.Song.Requester != nil {
+			requesters[e.Song.Requester.Username]++
+		}
+
+		started := e.StartedAt.UTC()
+		hours[fmt.Sprintf("%02d:00", started.Hour())]++
+		days[started.Weekday().String()]++
+	}
+
+	st.TopSongs = top(songs)
+	st.TopRequesters = top(requesters)
+	st.BusiestHours = top(hours)
+	st.BusiestDays = top(days)
+	return st
+}
+
+//top returns names with the most counts in descending order.
+func top(counts map[string]int) []types.Count {
+	res := make([]types.Count, 0, len(counts))
+	for name, count := range counts {
+		res = append(res, types.Count{Name: name, Count: count})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count == res[j].Count {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].Count > res[j].Count
+	})
+
+	if len(res) > topN {
+		res = res[:topN]
+	}
+	return res
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//play builds the history entry of the song played at the time for the duration.
+func play(title, requester string, at time.Time, d time.Duration) types.HistoryEntry {
+	e := types.HistoryEntry{
+		Song:      types.Song{Title: title},
+		StartedAt: at,
+		EndedAt:   at.Add(d),
+	}
+	if requester != "" {
+		e.Song.Requester = &discordgo.User{Username: requester}
+	}
+	return e
+}
+
+func TestCompute(t *testing.T) {
+	//Friday
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	since := day.Add(-7 * 24 * time.Hour)
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name    string
+		entries []types.HistoryEntry
+		want    types.Stats
+	}{
+		{
+			name: "empty",
+			want: types.Stats{Since: since, TopSongs: []types.Count{}, TopRequesters: []types.Count{}, BusiestHours: []types.Count{}, BusiestDays: []types.Count{}},
+		},
+		{
+			name: "counts and ties",
+			entries: []types.HistoryEntry{
+				play("B", "bob", day.Add(20*time.Hour), 3*time.Minute),
+				play("A", "alice", day.Add(20*time.Hour+3*time.Minute), 4*time.Minute),
+				play("A", "bob", day.Add(21*time.Hour), 4*time.Minute),
+				play("C", "", day.Add(24*time.Hour+20*time.Hour), time.Minute),
+			},
+			want: types.Stats{
+				Since:         since,
+				Plays:         4,
+				Listening:     12 * time.Minute,
+				TopSongs:      []types.Count{{Name: "A", Count: 2}, {Name: "B", Count: 1}, {Name: "C", Count: 1}},
+				TopRequesters: []types.Count{{Name: "bob", Count: 2}, {Name: "alice", Count: 1}},
+				BusiestHours:  []types.Count{{Name: "20:00", Count: 3}, {Name: "21:00", Count: 1}},
+				BusiestDays:   []types.Count{{Name: "Friday", Count: 3}, {Name: "Saturday", Count: 1}},
+			},
+		},
+		{
+			name: "times are in UTC",
+			entries: []types.HistoryEntry{
+				play("A", "", time.Date(2024, 3, 2, 1, 30, 0, 0, moscow), time.Minute),
+			},
+			want: types.Stats{
+				Since:         since,
+				Plays:         1,
+				Listening:     time.Minute,
+				TopSongs:      []types.Count{{Name: "A", Count: 1}},
+				TopRequesters: []types.Count{},
+				BusiestHours:  []types.Count{{Name: "22:00", Count: 1}},
+				BusiestDays:   []types.Count{{Name: "Friday", Count: 1}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compute(tt.entries, since); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compute() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeTopLength(t *testing.T) {
+	var entries []types.HistoryEntry
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	//Song n is played n+1 times
+	for n := 0; n < 8; n++ {
+		for i := 0; i <= n; i++ {
+			entries = append(entries, play(fmt.Sprintf("song %d", n), "", start, time.Minute))
+		}
+	}
+
+	st := Compute(entries, time.Time{})
+	want := []types.Count{{Name: "song 7", Count: 8}, {Name: "song 6", Count: 7}, {Name: "song 5", Count: 6}, {Name: "song 4", Count: 5}, {Name: "song 3", Count: 4}}
+	if !reflect.DeepEqual(st.TopSongs, want) {
+		t.Errorf("TopSongs = %v, want %v", st.TopSongs, want)
+	}
+	if st.Plays != 36 || st.Listening != 36*time.Minute {
+		t.Errorf("plays = %d, listening = %s", st.Plays, st.Listening)
+	}
+}
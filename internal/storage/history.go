@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+//AddHistory records the played song.
+//Entries are kept in the bucket of the guild ordered by the sequence number.
+func (s *Storage) AddHistory(e types.HistoryEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(bucketHistory).CreateBucketIfNotExists([]byte(e.GuildID))
+		if err != nil {
+			return err
+		}
+
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(sequenceKey(seq), data)
+	})
+}
+
+//History returns played songs of the guild starting from the most recent one.
+//total is the number of all recorded songs.
+func (s *Storage) History(gID string, offset, limit int) (entries []types.HistoryEntry, total int, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketHistory).Bucket([]byte(gID))
+		if b == nil {
+			return nil
+		}
+		//Entries are never removed, so sequence is the count
+		total = int(b.Sequence())
+
+		c := b.Cursor()
+		skipped := 0
+		for k, v := c.Last(); k != nil && len(entries) < limit; k, v = c.Prev() {
+			if skipped < offset {
+				skipped++
+				continue
+			}
+
+			var e types.HistoryEntry
+			err := json.Unmarshal(v, &e)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return
+}
+
+//HistorySince returns songs of the guild played after the time, most recent first.
+func (s *Storage) HistorySince(gID string, since time.Time) ([]types.HistoryEntry, error) {
+	var entries []types.HistoryEntry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketHistory).Bucket([]byte(gID))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var e types.HistoryEntry
+			err := json.Unmarshal(v, &e)
+			if err != nil {
+				return err
+			}
+			if e.StartedAt.Before(since) {
+				break
+			}
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+//LastSummary returns when the weekly recap was posted in the guild.
+//Zero time is returned if it was never posted.
+func (s *Storage) LastSummary(gID string) (time.Time, error) {
+	var t time.Time
+	_, err := s.get(bucketSummaries, gID, &t)
+	return t, err
+}
+
+//SetLastSummary saves when the weekly recap was posted in the guild.
+func (s *Storage) SetLastSummary(gID string, t time.Time) error {
+	return s.put(bucketSummaries, gID, t)
+}
+
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
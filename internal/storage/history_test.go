@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//addHistory records the number of songs played a minute apart, starting at the time.
+func addHistory(t *testing.T, s *Storage, gID string, n int, start time.Time) []types.HistoryEntry {
+	t.Helper()
+	var entries []types.HistoryEntry
+	for i := 0; i < n; i++ {
+		e := types.HistoryEntry{
+			GuildID:   gID,
+			Song:      types.Song{Title: fmt.Sprintf("song %d", i)},
+			StartedAt: start.Add(time.Duration(i) * time.Minute),
+			EndedAt:   start.Add(time.Duration(i)*time.Minute + 30*time.Second),
+			Skipped:   i%2 == 1,
+		}
+		if err := s.AddHistory(e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func titles(entries []types.HistoryEntry) []string {
+	var res []string
+	for _, e := range entries {
+		res = append(res, e.Song.Title)
+	}
+	return res
+}
+
+func TestHistoryPaging(t *testing.T) {
+	s := newTestStorage(t)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	recorded := addHistory(t, s, "1", 12, start)
+	addHistory(t, s, "2", 3, start)
+
+	tests := []struct {
+		name   string
+		gID    string
+		offset int
+		limit  int
+		want   []string
+		total  int
+	}{
+		{name: "first page", gID: "1", limit: 5, want: []string{"song 11", "song 10", "song 9", "song 8", "song 7"}, total: 12},
+		{name: "second page", gID: "1", offset: 5, limit: 5, want: []string{"song 6", "song 5", "song 4", "song 3", "song 2"}, total: 12},
+		{name: "last page is short", gID: "1", offset: 10, limit: 5, want: []string{"song 1", "song 0"}, total: 12},
+		{name: "past the end", gID: "1", offset: 20, limit: 5, total: 12},
+		{name: "another guild", gID: "2", limit: 5, want: []string{"song 2", "song 1", "song 0"}, total: 3},
+		{name: "guild without history", gID: "3", limit: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, total, err := s.History(tt.gID, tt.offset, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := titles(entries); !reflect.DeepEqual(got, tt.want) || total != tt.total {
+				t.Errorf("History() = %v, %d, want %v, %d", got, total, tt.want, tt.total)
+			}
+		})
+	}
+
+	entries, _, err := s.History("1", 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := recorded[len(recorded)-1]; !entries[0].StartedAt.Equal(want.StartedAt) || !entries[0].EndedAt.Equal(want.EndedAt) ||
+		entries[0].Skipped != want.Skipped || entries[0].GuildID != want.GuildID {
+		t.Errorf("entry = %+v, want %+v", entries[0], want)
+	}
+}
+
+func TestHistorySince(t *testing.T) {
+	s := newTestStorage(t)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	addHistory(t, s, "1", 5, start)
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  []string
+	}{
+		{name: "all", since: start.Add(-time.Hour), want: []string{"song 4", "song 3", "song 2", "song 1", "song 0"}},
+		{name: "start is included", since: start.Add(3 * time.Minute), want: []string{"song 4", "song 3"}},
+		{name: "none", since: start.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := s.HistorySince("1", tt.since)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := titles(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HistorySince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastSummary(t *testing.T) {
+	s := newTestStorage(t)
+	last, err := s.LastSummary("1")
+	if err != nil || !last.IsZero() {
+		t.Fatalf("summary which was never posted = %v, error = %v", last, err)
+	}
+
+	posted := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	if err := s.SetLastSummary("1", posted); err != nil {
+		t.Fatal(err)
+	}
+	s = reopen(t, s)
+	last, err = s.LastSummary("1")
+	if err != nil || !last.Equal(posted) {
+		t.Errorf("LastSummary() = %v, error = %v, want %v", last, err, posted)
+	}
+	if other, _ := s.LastSummary("2"); !other.IsZero() {
+		t.Errorf("summary of another guild = %v", other)
+	}
+}
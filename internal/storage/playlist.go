@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+//Playlist returns saved playlist, names are case insensitive.
+//found is false if there is no such playlist.
+func (s *Storage) Playlist(scope, ownerID, name string) (pl types.Playlist, found bool, err error) {
+	found, err = s.get(bucketPlaylists, playlistKey(scope, ownerID, name), &pl)
+	return
+}
+
+//Playlists returns all playlists of the owner.
+func (s *Storage) Playlists(scope, ownerID string) ([]types.Playlist, error) {
+	var playlists []types.Playlist
+	prefix := []byte(playlistKey(scope, ownerID, ""))
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketPlaylists).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var pl types.Playlist
+			err := json.Unmarshal(v, &pl)
+			if err != nil {
+				return err
+			}
+			playlists = append(playlists, pl)
+		}
+		return nil
+	})
+	return playlists, err
+}
+
+//SavePlaylist creates or overwrites the playlist.
+func (s *Storage) SavePlaylist(pl types.Playlist) error {
+	return s.put(bucketPlaylists, playlistKey(pl.Scope, pl.OwnerID, pl.Name), pl)
+}
+
+//DeletePlaylist removes the playlist.
+func (s *Storage) DeletePlaylist(scope, ownerID, name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPlaylists).Delete([]byte(playlistKey(scope, ownerID, name)))
+	})
+}
+
+func playlistKey(scope, ownerID, name string) string {
+	return scope + ":" + ownerID + ":" + strings.ToLower(name)
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+func TestPlaylists(t *testing.T) {
+	s := newTestStorage(t)
+
+	saved := []types.Playlist{
+		{Name: "Road Trip", Scope: types.ScopePersonal, OwnerID: "1", CreatorID: "1", Songs: []types.Song{{Title: "A"}}},
+		{Name: "Chill", Scope: types.ScopePersonal, OwnerID: "1", CreatorID: "1"},
+		//The same name for another user, the server and a user whose ID starts with the ID of the first one
+		{Name: "Road Trip", Scope: types.ScopePersonal, OwnerID: "2", CreatorID: "2", Songs: []types.Song{{Title: "B"}}},
+		{Name: "Road Trip", Scope: types.ScopeServer, OwnerID: "1", CreatorID: "3", Songs: []types.Song{{Title: "C"}}},
+		{Name: "Road Trip", Scope: types.ScopePersonal, OwnerID: "12", CreatorID: "12"},
+	}
+	for _, pl := range saved {
+		if err := s.SavePlaylist(pl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		scope   string
+		ownerID string
+		lookup  string
+		want    *types.Playlist
+	}{
+		{name: "exact name", scope: types.ScopePersonal, ownerID: "1", lookup: "Road Trip", want: &saved[0]},
+		{name: "name ignores case", scope: types.ScopePersonal, ownerID: "1", lookup: "ROAD trip", want: &saved[0]},
+		{name: "another owner", scope: types.ScopePersonal, ownerID: "2", lookup: "road trip", want: &saved[2]},
+		{name: "server scope", scope: types.ScopeServer, ownerID: "1", lookup: "road trip", want: &saved[3]},
+		{name: "missing name", scope: types.ScopePersonal, ownerID: "1", lookup: "Workout"},
+		{name: "missing in scope", scope: types.ScopeServer, ownerID: "1", lookup: "Chill"},
+		{name: "missing owner", scope: types.ScopePersonal, ownerID: "3", lookup: "Chill"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := s.Playlist(tt.scope, tt.ownerID, tt.lookup)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != (tt.want != nil) {
+				t.Fatalf("Playlist() found = %v, want %v", found, tt.want != nil)
+			}
+			if found && !reflect.DeepEqual(got, *tt.want) {
+				t.Errorf("Playlist() = %+v, want %+v", got, *tt.want)
+			}
+		})
+	}
+
+	lists := []struct {
+		scope   string
+		ownerID string
+		want    []string
+	}{
+		{scope: types.ScopePersonal, ownerID: "1", want: []string{"Chill", "Road Trip"}},
+		{scope: types.ScopePersonal, ownerID: "12", want: []string{"Road Trip"}},
+		{scope: types.ScopeServer, ownerID: "1", want: []string{"Road Trip"}},
+		{scope: types.ScopeServer, ownerID: "2", want: nil},
+	}
+	for _, tt := range lists {
+		playlists, err := s.Playlists(tt.scope, tt.ownerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := playlistNames(playlists); !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("Playlists(%s, %s) = %v, want %v", tt.scope, tt.ownerID, names, tt.want)
+		}
+	}
+}
+
+func TestPlaylistOverwriteAndDelete(t *testing.T) {
+	s := newTestStorage(t)
+	pl := types.Playlist{Name: "Mix", Scope: types.ScopePersonal, OwnerID: "1", Songs: []types.Song{{Title: "A"}}}
+	if err := s.SavePlaylist(pl); err != nil {
+		t.Fatal(err)
+	}
+
+	//Saving the name in another case replaces the playlist instead of adding one
+	pl.Name = "MIX"
+	pl.Songs = append(pl.Songs, types.Song{Title: "B"})
+	if err := s.SavePlaylist(pl); err != nil {
+		t.Fatal(err)
+	}
+	playlists, err := s.Playlists(types.ScopePersonal, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(playlists) != 1 || len(playlists[0].Songs) != 2 || playlists[0].Name != "MIX" {
+		t.Fatalf("playlists after overwrite = %+v", playlists)
+	}
+
+	if err := s.SavePlaylist(types.Playlist{Name: "Mix", Scope: types.ScopeServer, OwnerID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeletePlaylist(types.ScopePersonal, "1", "mix"); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := s.Playlist(types.ScopePersonal, "1", "Mix"); found {
+		t.Error("deleted playlist is found")
+	}
+	if _, found, _ := s.Playlist(types.ScopeServer, "1", "Mix"); !found {
+		t.Error("playlist of the server with the same name is deleted")
+	}
+}
+
+func playlistNames(playlists []types.Playlist) []string {
+	var names []string
+	for _, pl := range playlists {
+		names = append(names, pl.Name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,19 @@
+package storage
+
+import "github.com/relipocere/gotune/internal/discord/types"
+
+//Settings returns settings of the guild.
+//Default settings are returned if the guild has none saved.
+func (s *Storage) Settings(gID string) (types.Settings, error) {
+	set := types.DefaultSettings()
+	_, err := s.get(bucketSettings, gID, &set)
+	if err != nil {
+		return types.DefaultSettings(), err
+	}
+	return set, nil
+}
+
+//SaveSettings saves settings of the guild.
+func (s *Storage) SaveSettings(gID string, set types.Settings) error {
+	return s.put(bucketSettings, gID, set)
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+func TestSettings(t *testing.T) {
+	s := newTestStorage(t)
+
+	set, err := s.Settings("unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(set, types.DefaultSettings()) {
+		t.Errorf("guild without settings got %+v, want defaults", set)
+	}
+
+	tests := []struct {
+		name string
+		gID  string
+		set  types.Settings
+	}{
+		{
+			name: "every field",
+			gID:  "1",
+			set: types.Settings{
+				Volume:          50,
+				DJRole:          "10",
+				AnnounceChannel: "11",
+				Loop:            types.LoopQueue,
+				MaxQueue:        100,
+				Sources:         []string{types.SourceSearch, types.SourceLink},
+				SummaryChannel:  "12",
+				Autoplay:        true,
+				AlwaysOn:        true,
+				Prefix:          "!",
+				Locale:          "ru",
+			},
+		},
+		{
+			name: "zero values aren't replaced by defaults",
+			gID:  "2",
+			set:  types.Settings{Volume: 0, Loop: types.LoopOff},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.SaveSettings(tt.gID, tt.set); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.Settings(tt.gID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.set) {
+				t.Errorf("Settings() = %+v, want %+v", got, tt.set)
+			}
+		})
+	}
+
+	s = reopen(t, s)
+	got, err := s.Settings("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, tests[0].set) {
+		t.Errorf("settings aren't kept after reopening: %+v", got)
+	}
+}
+
+func TestSettingsOverwrite(t *testing.T) {
+	s := newTestStorage(t)
+	for _, volume := range []int{30, 70} {
+		set := types.DefaultSettings()
+		set.Volume = volume
+		if err := s.SaveSettings("1", set); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.Settings("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Volume != 70 {
+		t.Errorf("volume = %d, want the last saved 70", got.Volume)
+	}
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"github.com/relipocere/gotune/internal/discord/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+//State returns saved state of the guild player.
+//found is false if the guild has no saved state.
+func (s *Storage) State(gID string) (st types.PlayerState, found bool, err error) {
+	found, err = s.get(bucketStates, gID, &st)
+	return
+}
+
+//SaveState saves state of the guild player.
+func (s *Storage) SaveState(st types.PlayerState) error {
+	return s.put(bucketStates, st.GuildID, st)
+}
+
+//DeleteState removes saved state of the guild player.
+func (s *Storage) DeleteState(gID string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStates).Delete([]byte(gID))
+	})
+}
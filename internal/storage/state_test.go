@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+func TestState(t *testing.T) {
+	s := newTestStorage(t)
+
+	current := types.Song{
+		Title:     "Current",
+		Path:      "audio/current.webm",
+		Link:      "https://www.youtube.com/watch?v=current",
+		Requester: &discordgo.User{ID: "5", Username: "listener"},
+		Duration:  3 * time.Minute,
+		Chapters:  []types.Chapter{{Title: "Intro", Start: 0, End: 30 * time.Second}},
+	}
+	tests := []struct {
+		name string
+		st   types.PlayerState
+	}{
+		{
+			name: "playing with the queue",
+			st: types.PlayerState{
+				GuildID: "1",
+				VoiceID: "10",
+				TextID:  "11",
+				Current: &current,
+				Offset:  42,
+				Queue:   []types.Song{{Title: "Next", Link: "https://www.youtube.com/watch?v=next"}},
+			},
+		},
+		{
+			name: "only the queue",
+			st: types.PlayerState{
+				GuildID: "2",
+				VoiceID: "20",
+				TextID:  "21",
+				Queue:   []types.Song{{Title: "First"}, {Title: "Second"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.SaveState(tt.st); err != nil {
+				t.Fatal(err)
+			}
+			got, found, err := s.State(tt.st.GuildID)
+			if err != nil || !found {
+				t.Fatalf("State() found = %v, error = %v", found, err)
+			}
+			if !reflect.DeepEqual(got, tt.st) {
+				t.Errorf("State() = %+v, want %+v", got, tt.st)
+			}
+		})
+	}
+
+	s = reopen(t, s)
+	if _, found, _ := s.State("1"); !found {
+		t.Error("state isn't kept after reopening")
+	}
+
+	if err := s.DeleteState("1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, err := s.State("1"); found || err != nil {
+		t.Errorf("deleted state found = %v, error = %v", found, err)
+	}
+	if _, found, _ := s.State("2"); !found {
+		t.Error("state of another guild is deleted")
+	}
+	if err := s.DeleteState("unknown"); err != nil {
+		t.Errorf("deleting missing state error = %v", err)
+	}
+}
+
+func TestStateOverwrite(t *testing.T) {
+	s := newTestStorage(t)
+	for _, offset := range []int{10, 20} {
+		if err := s.SaveState(types.PlayerState{GuildID: "1", Offset: offset}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, _, err := s.State("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Offset != 20 {
+		t.Errorf("offset = %d, want the last saved 20", got.Offset)
+	}
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketSettings  = []byte("settings")
+	bucketStates    = []byte("states")
+	bucketPlaylists = []byte("playlists")
+	bucketHistory   = []byte("history")
+	bucketSummaries = []byte("summaries")
+)
+
+//Storage is embedded bolt database holding bot state.
+type Storage struct {
+	db *bolt.DB
+}
+
+//New opens database at the path, file is created if it doesn't exist.
+func New(path string) (*Storage, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketSettings, bucketStates, bucketPlaylists, bucketHistory, bucketSummaries} {
+			_, err := tx.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Storage{db: db}, nil
+}
+
+//Close closes the database.
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+//get decodes value stored under the key into v.
+//found is false if there is no such key.
+func (s *Storage) get(bucket []byte, key string, v interface{}) (found bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucket).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, v)
+	})
+	return
+}
+
+//put encodes v and stores it under the key.
+func (s *Storage) put(bucket []byte, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(key), data)
+	})
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	_ "embed"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+const (
+	apiPrefix = "/api/v1/"
+	//maxSearchLimit is the maximum number of search results
+	maxSearchLimit = 25
+)
+
+//openAPI describes the API for clients and code generators
+//
+//go:embed openapi.json
+var openAPI []byte
+
+//RegisterAPI mounts the JSON API controlling the players on the mux.
+//Requests are authenticated with the token in the Authorization header, "Bearer <token>".
+func (s *Server) RegisterAPI(mux *http.ServeMux) {
+	mux.HandleFunc(apiPrefix+"openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	mux.HandleFunc(apiPrefix+"guilds", s.authorized(s.listGuilds))
+	mux.HandleFunc(apiPrefix+"guilds/", s.authorized(s.routeAPIGuild))
+	mux.HandleFunc(apiPrefix+"search", s.authorized(s.search))
+	mux.HandleFunc(apiPrefix+"extract", s.authorized(s.extract))
+	mux.HandleFunc(apiPrefix, func(w http.ResponseWriter, r *http.Request) {
+		s.writeError(w, errorf(http.StatusNotFound, "unknown route %s %s", r.Method, r.URL.Path))
+	})
+}
+
+//routeAPIGuild routes /api/v1/guilds/<guild ID>/<resource> requests.
+func (s *Server) routeAPIGuild(w http.ResponseWriter, r *http.Request, u user) {
+	gID, resource, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, apiPrefix+"guilds/"), "/")
+	if !s.canControl(gID, u) {
+		s.writeError(w, errorf(http.StatusForbidden, "you can't control the player of this guild"))
+		return
+	}
+
+	route := r.Method + " " + resource
+	switch route {
+	case "GET player":
+		writeJSON(w, http.StatusOK, s.view(gID))
+	case "GET queue":
+		writeJSON(w, http.StatusOK, map[string][]songView{"songs": newSongViews(s.dispatcher.Queue(gID))})
+	case "POST queue":
+		s.addSongs(w, r, gID, u)
+	case "POST queue/move":
+		s.moveSong(w, r, gID)
+	case "POST queue/remove":
+		s.removeSong(w, r, gID)
+	case "POST skip":
+		s.skipSong(w, gID)
+	case "POST pause":
+		s.respondDone(w, s.dispatcher.Pause(gID))
+	case "POST resume":
+		s.respondDone(w, s.dispatcher.Resume(gID))
+	case "POST stop":
+		s.respondDone(w, s.dispatcher.Stop(gID))
+	case "POST seek":
+		s.seek(w, r, gID)
+	default:
+		s.writeError(w, errorf(http.StatusNotFound, "unknown route %s", route))
+	}
+}
+
+//respondDone responds with the error, or with no content if there is none.
+func (s *Server) respondDone(w http.ResponseWriter, err error) {
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+//seek is the handler seeking the current song to the position in seconds.
+func (s *Server) seek(w http.ResponseWriter, r *http.Request, gID string) {
+	var req struct {
+		Position float64 `json:"position"`
+	}
+	if err := decode(r, &req); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	song, _, ok := s.dispatcher.NowPlaying(gID)
+	if !ok {
+		s.writeError(w, types.ErrNotPlaying)
+		return
+	}
+	pos := time.Duration(req.Position * float64(time.Second))
+	if pos < 0 || song.Duration > 0 && pos >= song.Duration {
+		s.writeError(w, errorf(http.StatusBadRequest, "position %.0fs is outside of the song", req.Position))
+		return
+	}
+	s.respondDone(w, s.dispatcher.Seek(gID, int(pos/time.Second)))
+}
+
+//search is the handler finding videos by the query without downloading them.
+func (s *Server) search(w http.ResponseWriter, r *http.Request, _ user) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, errorf(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	query := strings.TrimSpace(r.URL.Query().Get("query"))
+	if query == "" {
+		s.writeError(w, errorf(http.StatusBadRequest, "query is empty"))
+		return
+	}
+	limit := 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxSearchLimit {
+			s.writeError(w, errorf(http.StatusBadRequest, "limit must be from 1 to %d", maxSearchLimit))
+			return
+		}
+	}
+
+	results, err := s.extractor.Search(query, limit)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	views := make([]songView, 0, len(results))
+	for _, res := range results {
+		views = append(views, songView{Title: res.Title, Link: res.Link})
+	}
+	writeJSON(w, http.StatusOK, map[string][]songView{"results": views})
+}
+
+//extract is the handler downloading songs by the query, so they're cached before they're played.
+func (s *Server) extract(w http.ResponseWriter, r *http.Request, _ user) {
+	if r.Method != http.MethodPost {
+		s.writeError(w, errorf(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	var req struct {
+		Query string `json:"query"`
+	}
+	if err := decode(r, &req); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		s.writeError(w, errorf(http.StatusBadRequest, "query is empty"))
+		return
+	}
+
+	songs, err := s.extractor.Get(strings.TrimSpace(req.Query))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]songView{"songs": newSongViews(songs)})
+}
@@ -0,0 +1,277 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	sessionCookie = "gotune_session"
+	stateCookie   = "gotune_oauth_state"
+	//sessionTTL is how long the user stays signed in
+	sessionTTL = 7 * 24 * time.Hour
+	//oauthTimeout is how long requests to Discord may take during sign-in
+	oauthTimeout = 10 * time.Second
+)
+
+//user is the signed in user of the dashboard.
+type user struct {
+	//ID is the Discord ID of the user, empty for the token user
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	//Local is set for the user signed in with the token, who can control every guild
+	Local bool `json:"local"`
+}
+
+type session struct {
+	user    user
+	expires time.Time
+}
+
+//auth signs users in with Discord OAuth2 or the local token.
+type auth struct {
+	token        string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	client       *http.Client
+
+	mux      *sync.Mutex
+	sessions map[string]session
+}
+
+func newAuth(opts Options) *auth {
+	return &auth{
+		token:        opts.Token,
+		clientID:     opts.ClientID,
+		clientSecret: opts.ClientSecret,
+		redirectURL:  opts.RedirectURL,
+		client:       &http.Client{Timeout: oauthTimeout},
+		mux:          &sync.Mutex{},
+		sessions:     make(map[string]session),
+	}
+}
+
+//discordEnabled checks whether users can sign in with Discord.
+func (a *auth) discordEnabled() bool {
+	return a.clientID != "" && a.clientSecret != "" && a.redirectURL != ""
+}
+
+//authenticate finds the user of the request by the bearer token or the session cookie.
+func (a *auth) authenticate(r *http.Request) (user, bool) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, found := strings.CutPrefix(header, "Bearer ")
+		return localUser(), found && a.validToken(token)
+	}
+
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return user{}, false
+	}
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	s, ok := a.sessions[cookie.Value]
+	if !ok || time.Now().After(s.expires) {
+		delete(a.sessions, cookie.Value)
+		return user{}, false
+	}
+	return s.user, true
+}
+
+func (a *auth) validToken(token string) bool {
+	return a.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1
+}
+
+func localUser() user {
+	return user{Name: "token", Local: true}
+}
+
+//signIn starts the session of the user, expired sessions are dropped.
+func (a *auth) signIn(w http.ResponseWriter, u user) {
+	id := randomID()
+	a.mux.Lock()
+	now := time.Now()
+	for key, s := range a.sessions {
+		if now.After(s.expires) {
+			delete(a.sessions, key)
+		}
+	}
+	a.sessions[id] = session{user: u, expires: now.Add(sessionTTL)}
+	a.mux.Unlock()
+
+	setCookie(w, sessionCookie, id, sessionTTL)
+}
+
+//loginToken signs in with the token posted from the login form.
+func (a *auth) loginToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin request", http.StatusForbidden)
+		return
+	}
+	if !a.validToken(r.PostFormValue("token")) {
+		http.Redirect(w, r, "/?error=token", http.StatusSeeOther)
+		return
+	}
+	a.signIn(w, localUser())
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+//loginDiscord redirects to Discord to authorize the dashboard.
+//State is remembered in the cookie to verify the callback.
+func (a *auth) loginDiscord(w http.ResponseWriter, r *http.Request) {
+	if !a.discordEnabled() {
+		http.NotFound(w, r)
+		return
+	}
+
+	state := randomID()
+	setCookie(w, stateCookie, state, 10*time.Minute)
+	query := url.Values{
+		"client_id":     {a.clientID},
+		"response_type": {"code"},
+		"scope":         {"identify"},
+		"redirect_uri":  {a.redirectURL},
+		"state":         {state},
+	}
+	http.Redirect(w, r, discordgo.EndpointDiscord+"oauth2/authorize?"+query.Encode(), http.StatusFound)
+}
+
+//callback exchanges the code Discord redirected with for the user.
+func (a *auth) callback(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil || r.URL.Query().Get("state") != cookie.Value {
+		http.Error(w, "invalid state", http.StatusBadRequest)
+		return
+	}
+	setCookie(w, stateCookie, "", -1)
+
+	u, err := a.discordUser(r.URL.Query().Get("code"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	a.signIn(w, u)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+//discordUser gets the user who authorized the dashboard.
+func (a *auth) discordUser(code string) (user, error) {
+	resp, err := a.client.PostForm(discordgo.EndpointOAuth2+"token", url.Values{
+		"client_id":     {a.clientID},
+		"client_secret": {a.clientSecret},
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {a.redirectURL},
+	})
+	if err != nil {
+		return user{}, fmt.Errorf("unable to exchange the code: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return user{}, fmt.Errorf("unable to exchange the code: %s", resp.Status)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return user{}, fmt.Errorf("unable to decode the token: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, discordgo.EndpointUser("@me"), nil)
+	if err != nil {
+		return user{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	resp, err = a.client.Do(req)
+	if err != nil {
+		return user{}, fmt.Errorf("unable to get the user: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return user{}, fmt.Errorf("unable to get the user: %s", resp.Status)
+	}
+	var du discordgo.User
+	if err := json.NewDecoder(resp.Body).Decode(&du); err != nil {
+		return user{}, fmt.Errorf("unable to decode the user: %w", err)
+	}
+
+	name := du.GlobalName
+	if name == "" {
+		name = du.Username
+	}
+	return user{ID: du.ID, Name: name}, nil
+}
+
+//logout ends the session.
+func (a *auth) logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin request", http.StatusForbidden)
+		return
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		a.mux.Lock()
+		delete(a.sessions, cookie.Value)
+		a.mux.Unlock()
+	}
+	setCookie(w, sessionCookie, "", -1)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+//sameOrigin checks that the form was posted from the dashboard itself,
+//so other sites can't sign the browser in or out.
+//Origin is preferred, Referer is checked for browsers which don't send it.
+func sameOrigin(r *http.Request) bool {
+	source := r.Header.Get("Origin")
+	if source == "" || source == "null" {
+		source = r.Header.Get("Referer")
+	}
+	u, err := url.Parse(source)
+	if err != nil || source == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+//setCookie sets the cookie hidden from scripts and not sent with cross-site requests,
+//negative age removes it.
+func setCookie(w http.ResponseWriter, name, value string, age time.Duration) {
+	maxAge := int(age / time.Second)
+	if age < 0 {
+		maxAge = -1
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+//randomID returns random hex string for session IDs and OAuth2 states.
+func randomID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
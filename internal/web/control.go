@@ -0,0 +1,174 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+//playRequest asks to queue songs found by the query.
+type playRequest struct {
+	Query string `json:"query"`
+	//ChannelID is the voice channel to play in, the player is moved to it if it's in another one.
+	//Voice channel of the player or the user is used if it's empty.
+	ChannelID string `json:"channelID"`
+	//Next puts the songs to the beginning of the queue
+	Next bool `json:"next"`
+}
+
+//play queues songs found by the query the same way play command does,
+//respecting allowed sources and the maximum queue length of the guild.
+func (s *Server) play(gID string, u user, req playRequest) ([]types.Song, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		return nil, errorf(http.StatusBadRequest, "query is empty")
+	}
+	set, err := s.settings.Settings(gID)
+	if err != nil {
+		s.log.Errorw(fmt.Sprintf("settings: %s", err.Error()), "guildID", gID)
+	}
+	if source := types.QuerySource(req.Query); !set.SourceAllowed(source) {
+		return nil, errorf(http.StatusForbidden, "requesting songs by %s is disabled", source)
+	}
+	if set.MaxQueue > 0 && len(s.dispatcher.Queue(gID)) >= set.MaxQueue {
+		return nil, errorf(http.StatusConflict, "the queue is full")
+	}
+
+	vID, move, err := s.voiceChannel(gID, u, req.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	textID := textChannel(set, s.dispatcher.TextChannel(gID))
+	if textID == "" {
+		return nil, errorf(http.StatusConflict, "set the announcement channel with /settings announce-channel or start the player from Discord")
+	}
+
+	songs, err := s.extractor.Get(req.Query)
+	if err != nil {
+		return nil, err
+	}
+	if queued := len(s.dispatcher.Queue(gID)); set.MaxQueue > 0 && queued+len(songs) > set.MaxQueue {
+		if queued >= set.MaxQueue {
+			return nil, errorf(http.StatusConflict, "the queue is full")
+		}
+		songs = songs[:set.MaxQueue-queued]
+	}
+	//Users controlling the player are allowed to use join command, so they can move it too
+	if move {
+		if err := s.dispatcher.Join(gID, vID); err != nil {
+			return nil, err
+		}
+	}
+	if !u.Local {
+		for ind := range songs {
+			songs[ind].Requester = &discordgo.User{ID: u.ID, Username: u.Name}
+		}
+	}
+
+	if req.Next {
+		err = s.dispatcher.PlayNext(gID, vID, textID, songs)
+	} else {
+		err = s.dispatcher.Play(gID, vID, textID, songs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return songs, nil
+}
+
+//textChannel chooses the text channel for announcements and errors of the player:
+//the announcement channel of the guild or the channel the player was requested from.
+//It's empty if neither is known.
+func textChannel(set types.Settings, playerText string) string {
+	if set.AnnounceChannel != "" {
+		return set.AnnounceChannel
+	}
+	return playerText
+}
+
+//voiceChannel chooses the voice channel to play in: the requested one, the one of the player,
+//or the one the user is in. Move reports whether the player is in another voice channel.
+func (s *Server) voiceChannel(gID string, u user, requested string) (vID string, move bool, err error) {
+	cur := s.dispatcher.VoiceChannel(gID)
+	if requested == "" || requested == cur {
+		if cur != "" {
+			return cur, false, nil
+		}
+		if !u.Local {
+			requested = s.guilds.MemberVoiceChannel(gID, u.ID)
+		}
+	}
+	if requested == "" {
+		return "", false, errorf(http.StatusBadRequest, "join a voice channel or choose one")
+	}
+	for _, ch := range s.guilds.VoiceChannels(gID) {
+		if ch.ID == requested {
+			return requested, cur != "", nil
+		}
+	}
+	return "", false, errorf(http.StatusBadRequest, "there is no voice channel %s in the guild", requested)
+}
+
+//addSongs is the handler queueing songs.
+func (s *Server) addSongs(w http.ResponseWriter, r *http.Request, gID string, u user) {
+	var req playRequest
+	if err := decode(r, &req); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	songs, err := s.play(gID, u, req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]songView{"songs": newSongViews(songs)})
+}
+
+//moveSong is the handler moving the queued song to another position.
+func (s *Server) moveSong(w http.ResponseWriter, r *http.Request, gID string) {
+	var req struct {
+		From int `json:"from"`
+		To   int `json:"to"`
+	}
+	if err := decode(r, &req); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	song, err := s.dispatcher.Move(gID, req.From, req.To)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]songView{"song": newSongView(song)})
+}
+
+//removeSong is the handler removing the song from the queue.
+func (s *Server) removeSong(w http.ResponseWriter, r *http.Request, gID string) {
+	var req struct {
+		Position int `json:"position"`
+	}
+	if err := decode(r, &req); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	song, err := s.dispatcher.Remove(gID, req.Position)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]songView{"song": newSongView(song)})
+}
+
+//skipSong is the handler skipping the current song.
+func (s *Server) skipSong(w http.ResponseWriter, gID string) {
+	song, err := s.dispatcher.Skip(gID)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]songView{"song": newSongView(song)})
+}
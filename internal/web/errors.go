@@ -0,0 +1,75 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//httpError is the error with the status it's responded with.
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+func errorf(status int, format string, args ...interface{}) error {
+	return &httpError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+//extractionStatuses are statuses of the extraction failures by reason.
+var extractionStatuses = map[string]int{
+	types.ReasonNotFound:       http.StatusNotFound,
+	types.ReasonUnavailable:    http.StatusUnprocessableEntity,
+	types.ReasonAgeRestricted:  http.StatusUnprocessableEntity,
+	types.ReasonSearchFailed:   http.StatusBadGateway,
+	types.ReasonDownloadFailed: http.StatusBadGateway,
+}
+
+//status maps errors of the dispatcher and the extractor to the response status.
+func status(err error) int {
+	var httpErr *httpError
+	var extractionErr *types.ExtractionError
+	var joinErr *types.JoinError
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr.status
+	case errors.As(err, &extractionErr):
+		if st, ok := extractionStatuses[extractionErr.Reason]; ok {
+			return st
+		}
+		return http.StatusBadGateway
+	case errors.Is(err, types.ErrQueueRange):
+		return http.StatusBadRequest
+	case errors.Is(err, types.ErrNoPlayer), errors.Is(err, types.ErrNotPlaying),
+		errors.Is(err, types.ErrAlreadyPaused), errors.Is(err, types.ErrNotPaused),
+		errors.Is(err, types.ErrAlreadyInChannel):
+		return http.StatusConflict
+	case errors.Is(err, types.ErrPlayerUnresponsive), errors.Is(err, types.ErrShuttingDown):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &joinErr):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+//writeError responds with the error message, internal errors are logged.
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	st := status(err)
+	if st >= http.StatusInternalServerError {
+		s.log.Errorw(fmt.Sprintf("web: %s", err.Error()), "status", st)
+	}
+	writeJSON(w, st, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
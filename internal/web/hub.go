@@ -0,0 +1,69 @@
+package web
+
+import (
+	"sync"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//clientBuffer is the number of events waiting for the slow client before new ones are dropped
+const clientBuffer = 32
+
+//hub fans out events of the guild players to the connected clients
+//and remembers the last state of every player.
+type hub struct {
+	mux     *sync.RWMutex
+	states  map[string]string
+	clients map[chan types.Event]string
+}
+
+func newHub() *hub {
+	return &hub{
+		mux:     &sync.RWMutex{},
+		states:  make(map[string]string),
+		clients: make(map[chan types.Event]string),
+	}
+}
+
+//handle is the event subscriber of the dispatcher.
+//Clients receive the whole player with every event, so the dropped ones are recovered by the next.
+func (h *hub) handle(e types.Event) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	if e.Type == types.EventPlayerDestroyed {
+		delete(h.states, e.GuildID)
+	} else {
+		h.states[e.GuildID] = e.State
+	}
+
+	for ch, gID := range h.clients {
+		if gID != e.GuildID {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+//state returns the last state of the guild player, empty if there is no player.
+func (h *hub) state(gID string) string {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+	return h.states[gID]
+}
+
+//join starts delivering events of the guild to the client, returned function stops it.
+func (h *hub) join(gID string) (<-chan types.Event, func()) {
+	ch := make(chan types.Event, clientBuffer)
+	h.mux.Lock()
+	h.clients[ch] = gID
+	h.mux.Unlock()
+
+	return ch, func() {
+		h.mux.Lock()
+		delete(h.clients, ch)
+		h.mux.Unlock()
+	}
+}
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//keepAliveInterval is how often comments are sent to the idle event stream, so proxies don't close it
+const keepAliveInterval = 30 * time.Second
+
+//songView is the song shown in the dashboard.
+type songView struct {
+	Title string `json:"title"`
+	Link  string `json:"link"`
+	//Duration is measured in seconds, 0 if unknown
+	Duration  float64 `json:"duration"`
+	Requester string  `json:"requester,omitempty"`
+}
+
+func newSongView(song types.Song) songView {
+	v := songView{Title: song.Title, Link: song.Link, Duration: song.Duration.Seconds()}
+	if song.Requester != nil {
+		v.Requester = song.Requester.Username
+	}
+	return v
+}
+
+func newSongViews(songs []types.Song) []songView {
+	views := make([]songView, 0, len(songs))
+	for _, song := range songs {
+		views = append(views, newSongView(song))
+	}
+	return views
+}
+
+//playerView is the guild player shown in the dashboard.
+type playerView struct {
+	GuildID string `json:"guildID"`
+	//State is empty if there is no player
+	State   string    `json:"state"`
+	VoiceID string    `json:"voiceID"`
+	Current *songView `json:"current"`
+	//Position is the playback time of the current song in seconds
+	Position float64       `json:"position"`
+	Queue    []songView    `json:"queue"`
+	Channels []channelView `json:"channels"`
+}
+
+type channelView struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+//view returns the current state of the guild player.
+func (s *Server) view(gID string) playerView {
+	v := playerView{
+		GuildID:  gID,
+		State:    s.hub.state(gID),
+		VoiceID:  s.dispatcher.VoiceChannel(gID),
+		Queue:    newSongViews(s.dispatcher.Queue(gID)),
+		Channels: []channelView{},
+	}
+	if song, position, ok := s.dispatcher.NowPlaying(gID); ok {
+		cur := newSongView(song)
+		v.Current = &cur
+		v.Position = position.Seconds()
+	}
+	for _, ch := range s.guilds.VoiceChannels(gID) {
+		v.Channels = append(v.Channels, channelView{ID: ch.ID, Name: ch.Name})
+	}
+	return v
+}
+
+//streamEvents sends the guild player as server-sent event, every time the player changes.
+//Events are named after the type of the player event.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, gID string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeError(w, errorf(http.StatusInternalServerError, "streaming isn't supported"))
+		return
+	}
+	events, leave := s.hub.join(gID)
+	defer leave()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	send := func(name string) error {
+		data, err := json.Marshal(s.view(gID))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+		flusher.Flush()
+		return err
+	}
+	if err := send("player"); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-events:
+			if err := send(string(e.Type)); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
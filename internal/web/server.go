@@ -0,0 +1,180 @@
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+
+	"go.uber.org/zap"
+)
+
+//maxBodySize is the maximum size of the request body
+const maxBodySize = 1 << 20
+
+//go:embed static
+var static embed.FS
+
+//Options configure sign-in to the dashboard and the API.
+type Options struct {
+	//Token signs in without Discord, empty disables it
+	Token string
+	//ClientID and ClientSecret of the Discord application, Discord sign-in is disabled unless both are set
+	ClientID     string
+	ClientSecret string
+	//RedirectURL is the callback of the dashboard added to the redirects of the application
+	RedirectURL string
+}
+
+//Server is the web dashboard and the JSON API showing and controlling the guild players.
+type Server struct {
+	log        *zap.SugaredLogger
+	dispatcher types.Dispatcher
+	extractor  types.Extractor
+	settings   types.SettingsStore
+	guilds     types.GuildDirectory
+	auth       *auth
+	hub        *hub
+}
+
+//New creates the dashboard, it starts receiving events of the players right away.
+func New(log *zap.SugaredLogger, d types.Dispatcher, e types.Extractor, settings types.SettingsStore, guilds types.GuildDirectory, opts Options) *Server {
+	s := &Server{
+		log:        log,
+		dispatcher: d,
+		extractor:  e,
+		settings:   settings,
+		guilds:     guilds,
+		auth:       newAuth(opts),
+		hub:        newHub(),
+	}
+	d.Subscribe(s.hub.handle)
+	return s
+}
+
+//Register mounts the dashboard on the mux.
+func (s *Server) Register(mux *http.ServeMux) {
+	files, _ := fs.Sub(static, "static")
+	mux.Handle("/", http.FileServer(http.FS(files)))
+	mux.HandleFunc("/login", s.auth.loginDiscord)
+	mux.HandleFunc("/login/token", s.auth.loginToken)
+	mux.HandleFunc("/callback", s.auth.callback)
+	mux.HandleFunc("/logout", s.auth.logout)
+
+	mux.HandleFunc("/dashboard/me", s.me)
+	mux.HandleFunc("/dashboard/guilds", s.authorized(s.listGuilds))
+	mux.HandleFunc("/dashboard/guilds/", s.authorized(s.routeGuild))
+}
+
+//authorized passes the signed in user to the handler, others are refused.
+//Requests changing state must be JSON, which cross-site forms can't send without the browser asking first.
+func (s *Server) authorized(h func(w http.ResponseWriter, r *http.Request, u user)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, ok := s.auth.authenticate(r)
+		if !ok {
+			s.writeError(w, errorf(http.StatusUnauthorized, "sign in or pass the token in the Authorization header"))
+			return
+		}
+		if r.Method != http.MethodGet {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "application/json" {
+				s.writeError(w, errorf(http.StatusUnsupportedMediaType, "request body must be JSON"))
+				return
+			}
+		}
+		h(w, r, u)
+	}
+}
+
+//me responds with the signed in user and the ways to sign in.
+func (s *Server) me(w http.ResponseWriter, r *http.Request) {
+	resp := struct {
+		User    *user `json:"user"`
+		Discord bool  `json:"discord"`
+		Token   bool  `json:"token"`
+	}{
+		Discord: s.auth.discordEnabled(),
+		Token:   s.auth.token != "",
+	}
+	if u, ok := s.auth.authenticate(r); ok {
+		resp.User = &u
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+//canControl checks whether the user may control the player of the guild the bot is in.
+func (s *Server) canControl(gID string, u user) bool {
+	if !u.Local {
+		return s.guilds.CanControl(gID, u.ID)
+	}
+	for _, g := range s.guilds.Guilds() {
+		if g.ID == gID {
+			return true
+		}
+	}
+	return false
+}
+
+//guildView is the guild in the list of the dashboard.
+type guildView struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+//listGuilds responds with the guilds the user may control.
+func (s *Server) listGuilds(w http.ResponseWriter, r *http.Request, u user) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, errorf(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+
+	guilds := []guildView{}
+	for _, g := range s.guilds.Guilds() {
+		if !u.Local && !s.guilds.CanControl(g.ID, u.ID) {
+			continue
+		}
+		guilds = append(guilds, guildView{ID: g.ID, Name: g.Name, State: s.hub.state(g.ID)})
+	}
+	writeJSON(w, http.StatusOK, guilds)
+}
+
+//routeGuild routes /dashboard/guilds/<guild ID>[/<action>] requests.
+func (s *Server) routeGuild(w http.ResponseWriter, r *http.Request, u user) {
+	gID, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/dashboard/guilds/"), "/")
+	if !s.canControl(gID, u) {
+		s.writeError(w, errorf(http.StatusForbidden, "you can't control the player of this guild"))
+		return
+	}
+
+	route := r.Method + " " + action
+	switch route {
+	case "GET ":
+		writeJSON(w, http.StatusOK, s.view(gID))
+	case "GET events":
+		s.streamEvents(w, r, gID)
+	case "POST queue":
+		s.addSongs(w, r, gID, u)
+	case "POST move":
+		s.moveSong(w, r, gID)
+	case "POST remove":
+		s.removeSong(w, r, gID)
+	case "POST skip":
+		s.skipSong(w, gID)
+	default:
+		s.writeError(w, errorf(http.StatusNotFound, "unknown route %s", route))
+	}
+}
+
+//decode reads the JSON body of the request.
+func decode(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodySize)).Decode(v)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "invalid body: %s", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+"use strict";
+
+const $ = (id) => document.getElementById(id);
+
+let guildID = "";
+let events = null;
+let player = null;
+//receivedAt is when the position of the current song was received, so progress can move between events
+let receivedAt = 0;
+
+async function request(method, path, body) {
+  const resp = await fetch(path, {
+    method: method,
+    headers: body ? { "Content-Type": "application/json" } : {},
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  const data = await resp.json();
+  if (!resp.ok) {
+    throw new Error(data.error || resp.statusText);
+  }
+  return data;
+}
+
+function showError(err) {
+  $("error").textContent = err ? err.message : "";
+  $("error").hidden = !err;
+}
+
+function formatTime(seconds) {
+  seconds = Math.floor(seconds);
+  const m = Math.floor(seconds / 60);
+  const s = String(seconds % 60).padStart(2, "0");
+  return `${m}:${s}`;
+}
+
+async function start() {
+  const me = await request("GET", "/dashboard/me");
+  if (!me.user) {
+    $("login").hidden = false;
+    $("login-discord").hidden = !me.discord;
+    $("login-token").hidden = !me.token;
+    $("login-error").hidden = new URLSearchParams(location.search).get("error") !== "token";
+    return;
+  }
+
+  $("account").hidden = false;
+  $("user-name").textContent = me.user.name;
+  $("dashboard").hidden = false;
+
+  const guilds = await request("GET", "/dashboard/guilds");
+  for (const g of guilds) {
+    $("guilds").add(new Option(g.state ? `${g.name} (${g.state})` : g.name, g.id));
+  }
+  if (guilds.length === 0) {
+    showError(new Error("There are no servers you can control"));
+    return;
+  }
+  selectGuild(guilds[0].id);
+}
+
+function selectGuild(id) {
+  guildID = id;
+  if (events) {
+    events.close();
+  }
+  events = new EventSource(`/dashboard/guilds/${id}/events`);
+  //Every event carries the whole player
+  const update = (e) => render(JSON.parse(e.data));
+  for (const name of ["player", "player_created", "player_destroyed", "state_changed", "track_started",
+    "track_finished", "track_skipped", "track_errored", "paused", "resumed", "queue_changed"]) {
+    events.addEventListener(name, update);
+  }
+}
+
+function render(p) {
+  player = p;
+  receivedAt = Date.now();
+
+  if (p.current) {
+    const link = document.createElement("a");
+    link.href = p.current.link;
+    link.target = "_blank";
+    link.rel = "noopener";
+    link.textContent = p.current.title;
+    $("now-playing").replaceChildren(link);
+  } else {
+    $("now-playing").textContent = "Nothing is playing";
+  }
+  $("state").textContent = p.state;
+  $("skip").disabled = !p.current;
+
+  //Voice channel can be chosen only when the player is started
+  const channel = $("channel");
+  channel.replaceChildren(new Option("My voice channel", ""));
+  for (const ch of p.channels) {
+    channel.add(new Option(ch.name, ch.id, false, ch.id === p.voiceID));
+  }
+  channel.disabled = p.voiceID !== "";
+
+  const items = p.queue.map((song, ind) => {
+    const item = $("queue-item").content.firstElementChild.cloneNode(true);
+    item.querySelector(".title").href = song.link;
+    item.querySelector(".title").textContent = song.title;
+    item.querySelector(".duration").textContent = song.duration ? formatTime(song.duration) : "";
+    item.dataset.position = ind + 1;
+    return item;
+  });
+  $("queue").replaceChildren(...items);
+  renderProgress();
+}
+
+function renderProgress() {
+  const progress = $("progress");
+  if (!player || !player.current || !player.current.duration) {
+    progress.hidden = true;
+    return;
+  }
+  let position = player.position;
+  if (player.state === "playing") {
+    position += (Date.now() - receivedAt) / 1000;
+  }
+  progress.hidden = false;
+  progress.max = player.current.duration;
+  progress.value = Math.min(position, player.current.duration);
+}
+
+$("guilds").addEventListener("change", (e) => selectGuild(e.target.value));
+
+$("skip").addEventListener("click", () => {
+  request("POST", `/dashboard/guilds/${guildID}/skip`, {}).then(() => showError(null), showError);
+});
+
+$("add").addEventListener("submit", (e) => {
+  e.preventDefault();
+  const body = { query: $("query").value, channelID: $("channel").value, next: $("next").checked };
+  $("query").disabled = true;
+  request("POST", `/dashboard/guilds/${guildID}/queue`, body)
+    .then(() => {
+      $("query").value = "";
+      showError(null);
+    }, showError)
+    .finally(() => {
+      $("query").disabled = false;
+    });
+});
+
+$("queue").addEventListener("click", (e) => {
+  const button = e.target.closest("button");
+  if (!button) {
+    return;
+  }
+  const position = Number(button.closest("li").dataset.position);
+  let call;
+  switch (button.dataset.action) {
+    case "up":
+      call = request("POST", `/dashboard/guilds/${guildID}/move`, { from: position, to: Math.max(position - 1, 1) });
+      break;
+    case "down":
+      call = request("POST", `/dashboard/guilds/${guildID}/move`, { from: position, to: Math.min(position + 1, player.queue.length) });
+      break;
+    case "remove":
+      call = request("POST", `/dashboard/guilds/${guildID}/remove`, { position: position });
+      break;
+  }
+  call.then(() => showError(null), showError);
+});
+
+setInterval(renderProgress, 1000);
+start().catch(showError);
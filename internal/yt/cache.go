@@ -0,0 +1,71 @@
+package yt
+
+import (
+	"sync"
+	"time"
+
+	"github.com/relipocere/gotune/internal/discord/types"
+)
+
+//searchCache is expiring cache of search results safe for concurrent use.
+type searchCache struct {
+	mux     *sync.Mutex
+	ttl     time.Duration
+	size    int
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	results []types.SearchResult
+	expires time.Time
+}
+
+//newSearchCache returns cache holding at most size entries for ttl.
+func newSearchCache(size int, ttl time.Duration) *searchCache {
+	return &searchCache{
+		mux:     &sync.Mutex{},
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+//Load gets results from the cache.
+func (c *searchCache) Load(key string) ([]types.SearchResult, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.results, true
+}
+
+//Store adds results to the cache.
+//If the cache is full, expired entries are evicted, then the oldest one.
+func (c *searchCache) Store(key string, results []types.SearchResult) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if len(c.entries) >= c.size {
+		now := time.Now()
+		var oldest string
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+				continue
+			}
+			if oldest == "" || e.expires.Before(c.entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		if len(c.entries) >= c.size {
+			delete(c.entries, oldest)
+		}
+	}
+
+	c.entries[key] = cacheEntry{
+		results: results,
+		expires: time.Now().Add(c.ttl),
+	}
+}
@@ -1,27 +1,51 @@
 package yt
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"time"
 
+	"os"
 	"os/exec"
-	"regexp"
 	"strings"
 
 	"github.com/relipocere/gotune/internal/discord/types"
+	"github.com/relipocere/gotune/internal/metrics"
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
 )
 
+const (
+	//maxInfoSize is the maximum size of the video info JSON
+	maxInfoSize = 16 * 1024 * 1024
+
+	//Search results are cached for searchCacheTTL, at most searchCacheSize queries
+	searchCacheTTL  = 10 * time.Minute
+	searchCacheSize = 1000
+)
+
 type Extractor struct {
 	folder string
 	api    *youtube.Service
+	cache  *searchCache
+	//ctx is cancelled on Close, killing running yt-dlp processes
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 //New creates YouTube API client and is wrapper for ytdl caller.
 func New(token, folder string) (Extractor, error) {
+	ctx, cancel := context.WithCancel(context.Background())
 	ext := Extractor{
 		folder: folder,
+		cache:  newSearchCache(searchCacheSize, searchCacheTTL),
+		ctx:    ctx,
+		cancel: cancel,
 	}
 
 	service, err := youtube.NewService(context.Background(), option.WithAPIKey(token))
@@ -33,91 +57,238 @@ func New(token, folder string) (Extractor, error) {
 	return ext, nil
 }
 
-//search returns ID of the best matching video.
+//search returns link and title of the best matching video.
 func (e Extractor) search(query string) (link, title string, err error) {
-	call := e.api.Search.List([]string{"id,snippet"}).Type("video").Q(query).MaxResults(1)
-	response, err := call.Do()
+	results, err := e.Search(query, 1)
 	if err != nil {
 		return "", "", err
 	}
 
-	if len(response.Items) < 1 || response.Items[0] == nil {
-		return "", "", fmt.Errorf("yt response is empty")
+	if len(results) < 1 {
+		return "", "", &types.ExtractionError{Reason: types.ReasonNotFound, Err: fmt.Errorf("yt response is empty")}
+	}
+	return results[0].Link, results[0].Title, nil
+}
+
+//Search returns videos matching the query.
+//Results are cached, so repeated queries don't spend API quota.
+func (e Extractor) Search(query string, limit int) ([]types.SearchResult, error) {
+	key := fmt.Sprintf("%d:%s", limit, strings.ToLower(strings.TrimSpace(query)))
+	if results, ok := e.cache.Load(key); ok {
+		metrics.SearchCache.WithLabelValues("hit").Inc()
+		return results, nil
 	}
+	metrics.SearchCache.WithLabelValues("miss").Inc()
 
-	if response.Items[0].Id == nil {
-		return "", "", fmt.Errorf("yt response doesn't cointain video ID")
+	call := e.api.Search.List([]string{"id,snippet"}).Type("video").Q(query).MaxResults(int64(limit))
+	response, err := call.Do()
+	if err != nil {
+		return nil, &types.ExtractionError{Reason: types.ReasonSearchFailed, Err: err}
 	}
 
-	link = fmt.Sprintf("https://www.youtube.com/watch?v=%s", response.Items[0].Id.VideoId)
-	if response.Items[0].Snippet != nil {
-		title = response.Items[0].Snippet.Title
+	var results []types.SearchResult
+	for _, item := range response.Items {
+		if item == nil || item.Id == nil || item.Id.VideoId == "" {
+			continue
+		}
+
+		res := types.SearchResult{
+			Link: fmt.Sprintf("https://www.youtube.com/watch?v=%s", item.Id.VideoId),
+		}
+		if item.Snippet != nil {
+			res.Title = html.UnescapeString(item.Snippet.Title)
+		}
+		results = append(results, res)
 	}
-	return
+
+	e.cache.Store(key, results)
+	return results, nil
 }
 
-//Get downloads the file into the specified folder then returns the filename.
+//Get downloads the file into the specified folder then returns the songs.
 func (e Extractor) Get(query string) ([]types.Song, error) {
-	var songs []types.Song
-
 	link, title, err := e.search(query)
 	if err != nil {
 		if !isLink(query) {
-			return songs, err
+			return nil, err
 		}
 		link = query
 	}
+	return e.download(link, title)
+}
+
+//Download downloads the songs of the link without searching, so it doesn't spend API quota.
+func (e Extractor) Download(link string) ([]types.Song, error) {
+	if !isLink(link) {
+		return nil, &types.ExtractionError{Reason: types.ReasonNotFound, Err: fmt.Errorf("%q isn't a YouTube link", link)}
+	}
+	return e.download(link, "")
+}
+
+//download runs yt-dlp for the link, title is used for videos without one.
+func (e Extractor) download(link, title string) ([]types.Song, error) {
+	var songs []types.Song
 
-	args := []string{"--no-colors", "--no-simulate",
+	//Video info is printed as JSON line per video, downloading isn't skipped
+	args := []string{"--no-colors", "--no-simulate", "--dump-json",
 		"--max-downloads", "10",
 		"-P", e.folder,
 		"--format", "ba",
 		"--restrict-filenames",
 		"-o", "%(title)s.%(ext)s", link}
 
-	cmd := exec.Command("yt-dlp", args...)
-	binOut, _ := cmd.CombinedOutput()
-	out := string(binOut)
-
-	paths := extractPaths(out, e.folder)
-	if len(paths) < 1 {
-		return songs, fmt.Errorf("no song files were found: %s", out)
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(e.ctx, "yt-dlp", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	//Exit error is ignored, because yt-dlp exits with error when max downloads are reached
+	metrics.YtdlpProcesses.Inc()
+	err := cmd.Run()
+	metrics.YtdlpProcesses.Dec()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return songs, &types.ExtractionError{Reason: types.ReasonDownloadFailed, Err: err}
 	}
 
-	unknownTitle := title == ""
-	for _, path := range paths {
-		if unknownTitle {
-			title = extractTitleFromPath(path, e.folder)
+	scanner := bufio.NewScanner(&stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxInfoSize)
+	for scanner.Scan() {
+		var info videoInfo
+		err := json.Unmarshal(scanner.Bytes(), &info)
+		if err != nil {
+			continue
 		}
+		//Info is printed even if the download fails
+		if _, err := os.Stat(info.Filename); err != nil {
+			continue
+		}
+		songs = append(songs, info.song(title))
+	}
 
-		songs = append(songs, types.Song{
-			Title: title,
-			Path:  path,
-			Link:  link,
-		})
+	if len(songs) < 1 {
+		return songs, &types.ExtractionError{
+			Reason: downloadFailure(stderr.String()),
+			Err:    fmt.Errorf("no song files were found: %s", stderr.String()),
+		}
 	}
 	return songs, nil
 }
 
+//downloadFailure determines the reason of the failed download from yt-dlp errors.
+func downloadFailure(stderr string) string {
+	msg := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(msg, "confirm your age"), strings.Contains(msg, "age-restricted"):
+		return types.ReasonAgeRestricted
+	case strings.Contains(msg, "video unavailable"), strings.Contains(msg, "private video"),
+		strings.Contains(msg, "not available"), strings.Contains(msg, "has been removed"):
+		return types.ReasonUnavailable
+	case strings.Contains(msg, "unsupported url"), strings.Contains(msg, "incomplete youtube id"):
+		return types.ReasonNotFound
+	}
+	return types.ReasonDownloadFailed
+}
+
 func isLink(s string) bool {
 	return strings.HasPrefix(s, "https://www.youtube.com/watch")
 }
 
-//extractTitleFromPath extracts readable title from the file name.
-func extractTitleFromPath(path, folder string) string {
-	fLen := len(folder)
-	//Accounting for closing /
-	if !strings.HasSuffix(folder, "/") {
-		fLen += 1
+//Related downloads a song related to the seeds, which isn't among the recent songs.
+//Candidates are taken from YouTube mixes of the seeds, most recent seed first.
+func (e Extractor) Related(seeds, recent []types.Song) (types.Song, error) {
+	played := make(map[string]bool, len(recent)+len(seeds))
+	for _, songs := range [][]types.Song{recent, seeds} {
+		for _, song := range songs {
+			if id := song.VideoID(); id != "" {
+				played[id] = true
+			}
+		}
+	}
+
+	for _, seed := range seeds {
+		id := seed.VideoID()
+		if id == "" {
+			continue
+		}
+
+		candidates, err := e.mixIDs(id)
+		if err != nil {
+			return types.Song{}, err
+		}
+
+		for _, candidate := range candidates {
+			if played[candidate] {
+				continue
+			}
+
+			songs, err := e.download(fmt.Sprintf("https://www.youtube.com/watch?v=%s", candidate), "")
+			if err != nil || len(songs) < 1 {
+				played[candidate] = true
+				continue
+			}
+			return songs[0], nil
+		}
+	}
+	return types.Song{}, &types.ExtractionError{Reason: types.ReasonNotFound, Err: fmt.Errorf("no related songs were found")}
+}
+
+//mixIDs lists IDs of the videos in the YouTube mix of the video.
+func (e Extractor) mixIDs(id string) ([]string, error) {
+	link := fmt.Sprintf("https://www.youtube.com/watch?v=%s&list=RD%s", id, id)
+	args := []string{"--no-colors", "--flat-playlist",
+		"--playlist-end", "25",
+		"--print", "id", link}
+
+	metrics.YtdlpProcesses.Inc()
+	out, err := exec.CommandContext(e.ctx, "yt-dlp", args...).Output()
+	metrics.YtdlpProcesses.Dec()
+	if err != nil {
+		return nil, &types.ExtractionError{Reason: types.ReasonDownloadFailed, Err: fmt.Errorf("unable to list the mix: %w", err)}
+	}
+	return strings.Fields(string(out)), nil
+}
+
+//Close kills running yt-dlp processes, extractor can't download songs afterwards.
+func (e Extractor) Close() {
+	e.cancel()
+}
+
+//videoInfo is the part of yt-dlp video info.
+type videoInfo struct {
+	Title      string  `json:"title"`
+	WebpageURL string  `json:"webpage_url"`
+	Filename   string  `json:"filename"`
+	Duration   float64 `json:"duration"`
+	Chapters   []struct {
+		Title     string  `json:"title"`
+		StartTime float64 `json:"start_time"`
+		EndTime   float64 `json:"end_time"`
+	} `json:"chapters"`
+}
+
+//song converts video info to the song.
+//Search title is used, if video has no title.
+func (v videoInfo) song(searchTitle string) types.Song {
+	song := types.Song{
+		Title:    v.Title,
+		Path:     v.Filename,
+		Link:     v.WebpageURL,
+		Duration: seconds(v.Duration),
+	}
+	if song.Title == "" {
+		song.Title = searchTitle
+	}
+
+	for _, ch := range v.Chapters {
+		song.Chapters = append(song.Chapters, types.Chapter{
+			Title: ch.Title,
+			Start: seconds(ch.StartTime),
+			End:   seconds(ch.EndTime),
+		})
 	}
-	extPos := strings.LastIndex(path, ".")
-	return strings.Replace(path[fLen:extPos], "_", " ", -1)
+	return song
 }
 
-//extractPaths gets the names of the downloaded songs.
-func extractPaths(output, folder string) []string {
-	expr := fmt.Sprintf(`%s/.+\.(3gp|aac|flv|m4a|mp3|mp4|ogg|wav|webm)`, folder)
-	re := regexp.MustCompile(expr)
-	found := re.FindAllString(output, -1)
-	return found
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
 }